@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/check"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+// runCheck runs all client and channel checks once and prints the report.
+// It returns exitError when queries of any path failed and
+// exitThresholdsBreached when any threshold is breached.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := fs.String("config", "./config.yml", "path to config file")
+	pathsDir := fs.String("paths-dir", "", "read IBC paths from local registry directory instead of GitHub")
+	output := fs.String("output", check.OutputTable, "output format: table or json")
+	expiry := fs.Duration("expiry-threshold", 72*time.Hour, "fail when a client expires sooner than this")
+	stuck := fs.Int("stuck-threshold", 0, "fail when a channel has more stuck packets than this")
+	logLevel := log.LevelFlagSet(fs)
+
+	_ = fs.Parse(args)
+	log.SetLevel(*logLevel)
+
+	cfg, err := config.NewConfig(*configPath)
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	ctx := context.Background()
+
+	var paths []*config.IBCData
+	if *pathsDir != "" {
+		paths, err = config.LocalIBCPaths(*pathsDir)
+	} else {
+		paths, err = cfg.IBCPaths(ctx)
+	}
+
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to get IBC paths: %v", err))
+		return exitError
+	}
+
+	report := check.Run(ctx, paths, cfg.GetRPCsMap(), check.Thresholds{
+		ClientExpiry: *expiry,
+		StuckPackets: *stuck,
+	})
+
+	if err := report.Write(os.Stdout, *output); err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	switch {
+	case report.Errored():
+		return exitError
+	case report.Failed():
+		return exitThresholdsBreached
+	}
+
+	return exitOK
+}
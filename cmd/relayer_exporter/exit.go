@@ -0,0 +1,13 @@
+package main
+
+// Exit codes of subcommands.
+const (
+	exitOK = iota
+	// exitThresholdsBreached is returned when check finds a breached
+	// threshold.
+	exitThresholdsBreached
+	// exitError is returned when queries fail.
+	exitError
+	// exitInvalid is returned when validation finds problems.
+	exitInvalid
+)
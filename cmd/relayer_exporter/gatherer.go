@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// statusGatherer gathers collectors filling the status store for background
+// evaluations, at most once per interval, so notifier and remediator
+// sharing it don't query chains twice.
+type statusGatherer struct {
+	mu       sync.Mutex
+	gatherer prometheus.Gatherer
+	interval time.Duration
+	last     time.Time
+	now      func() time.Time
+}
+
+func newStatusGatherer(gatherer prometheus.Gatherer, interval time.Duration) *statusGatherer {
+	return &statusGatherer{gatherer: gatherer, interval: interval, now: time.Now}
+}
+
+// Gather gathers collectors unless they were gathered within interval.
+// Gathered metrics are dropped, evaluations read the status store.
+func (g *statusGatherer) Gather() ([]*dto.MetricFamily, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now := g.now(); now.Sub(g.last) >= g.interval {
+		g.last = now
+
+		if _, err := g.gatherer.Gather(); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// statusGatherInterval returns half of the shortest evaluation interval of
+// notifications and remediation, so each evaluation sees fresh status.
+func statusGatherInterval(cfg *config.Config) time.Duration {
+	interval := time.Duration(0)
+
+	if cfg.Notifications != nil {
+		interval = cfg.Notifications.GetInterval()
+	}
+
+	if cfg.Remediation != nil && (interval == 0 || cfg.Remediation.GetInterval() < interval) {
+		interval = cfg.Remediation.GetInterval()
+	}
+
+	return interval / 2
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/archway-network/relayer_exporter/pkg/check"
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+// runPackets queries stuck packets of a path once and prints them with
+// their metadata.
+func runPackets(args []string) int {
+	fs := flag.NewFlagSet("packets", flag.ExitOnError)
+	configPath := fs.String("config", "./config.yml", "path to config file")
+	pathsDir := fs.String("paths-dir", "", "read IBC paths from local registry directory instead of GitHub")
+	pathName := fs.String("path", "", "IBC path in <chain1>-<chain2> format")
+	channel := fs.String("channel", "", "list only packets of channel of either chain")
+	output := fs.String("output", check.OutputTable, "output format: table or json")
+	logLevel := log.LevelFlagSet(fs)
+
+	_ = fs.Parse(args)
+	log.SetLevel(*logLevel)
+
+	if *pathName == "" {
+		fmt.Fprintln(os.Stderr, "-path must be specified")
+		return exitError
+	}
+
+	cfg, err := config.NewConfig(*configPath)
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	ctx := context.Background()
+
+	var paths []*config.IBCData
+	if *pathsDir != "" {
+		paths, err = config.LocalIBCPaths(*pathsDir)
+	} else {
+		paths, err = cfg.IBCPaths(ctx)
+	}
+
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to get IBC paths: %v", err))
+		return exitError
+	}
+
+	var path *config.IBCData
+
+	for _, p := range paths {
+		if p.HasName(*pathName) {
+			path = p
+		}
+	}
+
+	if path == nil {
+		log.Error(fmt.Sprintf("Unknown path %q", *pathName))
+		return exitError
+	}
+
+	rpcs := cfg.GetRPCsMap()
+	cache := collector.NewPacketCache()
+
+	status := collector.GetPathStatus(ctx, path, rpcs, cache)
+	if status.LastError != "" {
+		log.Error(status.LastError)
+		return exitError
+	}
+
+	packets := cache.StuckPackets(ctx, rpcs, status, *channel)
+
+	if err := check.WritePackets(os.Stdout, packets, *output); err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	return exitOK
+}
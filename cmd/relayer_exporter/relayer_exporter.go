@@ -17,7 +17,12 @@ import (
 
 	"github.com/archway-network/relayer_exporter/pkg/collector"
 	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
 	log "github.com/archway-network/relayer_exporter/pkg/logger"
+	"github.com/archway-network/relayer_exporter/pkg/notifier"
+	"github.com/archway-network/relayer_exporter/pkg/remediation"
+	"github.com/archway-network/relayer_exporter/pkg/server"
+	"github.com/archway-network/relayer_exporter/pkg/store"
 )
 
 var (
@@ -30,22 +35,193 @@ func getVersion() string {
 	return fmt.Sprintf("version: %s commit: %s date: %s", version, commit, date)
 }
 
+// exporter holds state shared by collectors and HTTP handlers across
+// configuration refreshes.
+type exporter struct {
+	registry *prometheus.Registry
+	// statusRegistry holds only collectors filling the status store. It is
+	// gathered by notifier and remediator when the exporter isn't scraped.
+	statusRegistry *prometheus.Registry
+	cache          *collector.SampleCache
+	status         *collector.StatusStore
+	history        *collector.BalanceHistory
+	fees           *collector.FeeLedger
+	paths          *collector.PathHistory
+	packets        *collector.PacketCache
+	denoms         *collector.DenomCache
+	targets        *server.Targets
+	health         *server.Health
+}
+
+// newExporter returns exporter with history loaded from db, which may be
+// nil to keep history in memory only.
+func newExporter(readyChainsPercent float64, db *store.Store, balanceWindow time.Duration) (*exporter, error) {
+	history, err := collector.NewBalanceHistory(balanceWindow, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance history: %w", err)
+	}
+
+	fees, err := collector.NewFeeLedger(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee ledger: %w", err)
+	}
+
+	paths, err := collector.NewPathHistory(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load path history: %w", err)
+	}
+
+	return &exporter{
+		registry:       prometheus.NewRegistry(),
+		statusRegistry: prometheus.NewRegistry(),
+		cache:          collector.NewSampleCache(),
+		status:         collector.NewStatusStore(),
+		history:        history,
+		fees:           fees,
+		paths:          paths,
+		packets:        collector.NewPacketCache(),
+		denoms:         collector.NewDenomCache(),
+		targets:        &server.Targets{},
+		health:         server.NewHealth(readyChainsPercent),
+	}, nil
+}
+
 // refreshCollectors updates the collectors with new configuration
-func refreshCollectors(ctx context.Context, cfg *config.Config, registry *prometheus.Registry) error {
-	err := refreshIBCCollector(ctx, cfg, registry)
+func (e *exporter) refreshCollectors(ctx context.Context, cfg *config.Config) error {
+	err := e.refreshIBCCollector(ctx, cfg)
 	if err != nil {
 		return err
 	}
 
-	err = refreshWalletBalanceCollector(cfg, registry)
+	err = e.refreshWalletBalanceCollector(cfg)
 	if err != nil {
 		return err
 	}
 
+	e.refreshFeeCollector(cfg)
+	e.refreshEscrowCollector(cfg)
+	e.refreshIncentiveCollector(cfg)
+	e.refreshICACollector(cfg)
+	e.refreshRouteCollector(cfg)
+
 	return nil
 }
 
-func refreshWalletBalanceCollector(cfg *config.Config, registry *prometheus.Registry) error {
+// refreshRouteCollector updates the route collector with configured packet
+// forward routes and IBC paths fetched by the last refresh.
+func (e *exporter) refreshRouteCollector(cfg *config.Config) {
+	e.targets.SetRoutes(cfg.ForwardRoutes)
+	e.registry.Unregister(collector.RouteCollector{})
+
+	if len(cfg.ForwardRoutes) == 0 {
+		return
+	}
+
+	e.registry.MustRegister(collector.RouteCollector{
+		Routes:  cfg.ForwardRoutes,
+		Paths:   e.targets.Paths(),
+		Status:  e.status,
+		History: e.paths,
+	})
+}
+
+// refreshIncentiveCollector updates the ICS-29 incentive collector with IBC
+// paths having fee-enabled channels.
+func (e *exporter) refreshIncentiveCollector(cfg *config.Config) {
+	paths := []*config.IBCData{}
+
+	for _, p := range e.targets.Paths() {
+		for _, c := range p.Channels {
+			if ibc.IsFeeEnabled(c.Version) {
+				paths = append(paths, p)
+				break
+			}
+		}
+	}
+
+	e.registry.Unregister(collector.IncentiveCollector{})
+
+	if len(paths) == 0 {
+		return
+	}
+
+	e.registry.MustRegister(collector.IncentiveCollector{
+		RPCs:   cfg.GetRPCsMap(),
+		Paths:  paths,
+		Denoms: e.denoms,
+	})
+}
+
+// refreshICACollector updates the interchain accounts collector with IBC
+// paths having ICA channels.
+func (e *exporter) refreshICACollector(cfg *config.Config) {
+	paths := []*config.IBCData{}
+
+	for _, p := range e.targets.Paths() {
+		for _, c := range p.Channels {
+			if ibc.IsICAChannel(c) {
+				paths = append(paths, p)
+				break
+			}
+		}
+	}
+
+	e.registry.Unregister(collector.ICACollector{})
+
+	if len(paths) == 0 {
+		return
+	}
+
+	e.registry.MustRegister(collector.ICACollector{
+		RPCs:  cfg.GetRPCsMap(),
+		Paths: paths,
+	})
+}
+
+// refreshEscrowCollector updates the escrow collector with IBC paths fetched
+// by the last refresh.
+func (e *exporter) refreshEscrowCollector(cfg *config.Config) {
+	if cfg.Escrow == nil {
+		return
+	}
+
+	paths := []*config.IBCData{}
+
+	for _, p := range e.targets.Paths() {
+		if cfg.Escrow.Includes(p) {
+			paths = append(paths, p)
+		}
+	}
+
+	e.registry.Unregister(collector.EscrowCollector{})
+
+	e.registry.MustRegister(collector.EscrowCollector{
+		RPCs:   cfg.GetRPCsMap(),
+		Paths:  paths,
+		Denoms: e.denoms,
+	})
+}
+
+// refreshFeeCollector updates the fee collector with accounts and IBC paths
+// fetched by the last refresh.
+func (e *exporter) refreshFeeCollector(cfg *config.Config) {
+	if cfg.Fees == nil || len(cfg.Accounts) == 0 {
+		return
+	}
+
+	e.registry.Unregister(collector.FeeCollector{})
+
+	e.registry.MustRegister(collector.FeeCollector{
+		RPCs:     cfg.GetRPCsMap(),
+		Paths:    e.targets.Paths(),
+		Accounts: cfg.Accounts,
+		MaxPages: cfg.Fees.GetMaxPages(),
+		Ledger:   e.fees,
+		Denoms:   e.denoms,
+	})
+}
+
+func (e *exporter) refreshWalletBalanceCollector(cfg *config.Config) error {
 	if len(cfg.Accounts) == 0 {
 		log.Warn("No accounts configured, skipping wallet balance collector refresh")
 		return nil
@@ -53,22 +229,32 @@ func refreshWalletBalanceCollector(cfg *config.Config, registry *prometheus.Regi
 
 	rpcs := cfg.GetRPCsMap()
 	// Unregister existing collectors
-	registry.Unregister(collector.WalletBalanceCollector{})
+	e.registry.Unregister(collector.WalletBalanceCollector{})
+	e.statusRegistry.Unregister(collector.WalletBalanceCollector{})
 
 	// Create and register new collector
 	balancesCollector := collector.WalletBalanceCollector{
-		RPCs:     rpcs,
-		Accounts: cfg.Accounts,
+		RPCs:          rpcs,
+		Accounts:      cfg.Accounts,
+		ErrorPolicies: cfg.GetErrorPolicies(),
+		Cache:         e.cache,
+		Status:        e.status,
+		History:       e.history,
+		Denoms:        e.denoms,
 	}
 
-	registry.MustRegister(balancesCollector)
+	e.registry.MustRegister(balancesCollector)
+	e.statusRegistry.MustRegister(balancesCollector)
+	e.targets.SetAccounts(rpcs, cfg.Accounts)
 
 	return nil
 }
 
 // refreshIBCCollectors updates the IBC collector with new paths
-func refreshIBCCollector(ctx context.Context, cfg *config.Config, registry *prometheus.Registry) error {
+func (e *exporter) refreshIBCCollector(ctx context.Context, cfg *config.Config) error {
 	paths, err := cfg.IBCPaths(ctx)
+	e.health.SetRegistry(err)
+
 	if err != nil {
 		log.Warn("Failed to get IBC paths, skipping IBC collector refresh", zap.Error(err))
 		return nil
@@ -77,24 +263,48 @@ func refreshIBCCollector(ctx context.Context, cfg *config.Config, registry *prom
 	if len(paths) > 0 {
 		rpcs := cfg.GetRPCsMap()
 		// Unregister existing collector
-		registry.Unregister(collector.IBCCollector{})
+		e.registry.Unregister(collector.IBCCollector{})
+		e.statusRegistry.Unregister(collector.IBCCollector{})
 
 		// Create and register new collector
 		ibcCollector := collector.IBCCollector{
-			RPCs:  rpcs,
-			Paths: paths,
+			RPCs:          rpcs,
+			Paths:         paths,
+			ErrorPolicies: cfg.GetErrorPolicies(),
+			Cache:         e.cache,
+			Status:        e.status,
+			History:       e.paths,
+			Packets:       e.packets,
 		}
-		registry.MustRegister(ibcCollector)
+		e.registry.MustRegister(ibcCollector)
+		e.statusRegistry.MustRegister(ibcCollector)
+		e.targets.SetPaths(rpcs, paths)
 	}
 
 	return nil
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		case "rules":
+			os.Exit(runRules(os.Args[2:]))
+		case "packets":
+			os.Exit(runPackets(os.Args[2:]))
+		}
+	}
+
 	port := flag.Int("p", 8008, "Server port")
 	version := flag.Bool("version", false, "Print version")
 	configPath := flag.String("config", "./config.yml", "path to config file")
 	refreshInterval := flag.Duration("refresh", 5*time.Minute, "Configuration refresh interval")
+	readyChainsPercent := flag.Float64(
+		"ready-chains-percent", 50, "Minimum percentage of reachable chain RPCs for the exporter to be ready",
+	)
 	logLevel := log.LevelFlag()
 
 	flag.Parse()
@@ -117,10 +327,23 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	registry := prometheus.NewRegistry()
+	var db *store.Store
+
+	if cfg.Store != nil {
+		db, err = store.Open(cfg.Store.Path)
+		if err != nil {
+			log.Fatal(fmt.Sprintf("Failed to open store: %v", err))
+		}
+		defer db.Close()
+	}
+
+	exp, err := newExporter(*readyChainsPercent, db, cfg.GetBalanceHistory().Window)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 
 	// Initial setup of collectors
-	if err := refreshCollectors(ctx, cfg, registry); err != nil {
+	if err := exp.refreshCollectors(ctx, cfg); err != nil {
 		log.Fatal(err.Error())
 	}
 
@@ -138,6 +361,8 @@ func main() {
 		ticker := time.NewTicker(*refreshInterval)
 		defer ticker.Stop()
 
+		exp.health.CheckChains(ctx, cfg.GetRPCsMap())
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -146,32 +371,77 @@ func main() {
 			case <-ticker.C:
 				log.Info("Refreshing configuration and collectors")
 
-				if err := refreshCollectors(ctx, cfg, registry); err != nil {
+				if err := exp.refreshCollectors(ctx, cfg); err != nil {
 					log.Error(fmt.Sprintf("Failed to refresh collectors: %v", err))
 					continue
 				}
 
+				exp.health.CheckChains(ctx, cfg.GetRPCsMap())
+
 				log.Info("Successfully refreshed configuration and collectors")
 			}
 		}
 	}()
 
+	gatherer := newStatusGatherer(exp.statusRegistry, statusGatherInterval(cfg))
+
+	if cfg.Notifications != nil {
+		n := notifier.New(cfg.Notifications, exp.status, gatherer, notifier.Senders(cfg.Notifications))
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			n.Run(ctx)
+		}()
+	}
+
+	if cfg.Remediation != nil {
+		audit, err := remediation.OpenAuditLog(cfg.Remediation.AuditLog)
+		if err != nil {
+			log.Fatal(fmt.Sprintf("Failed to open audit log: %v", err))
+		}
+		defer audit.Close()
+
+		sender := remediation.ChainSender{
+			RPCs:    cfg.GetRPCsMap(),
+			Keyring: cfg.Keyring,
+			Memo:    cfg.Remediation.Memo,
+		}
+		r := remediation.New(cfg.Remediation, exp.status, exp.paths, gatherer, sender, audit)
+		exp.registry.MustRegister(r)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			r.Run(ctx)
+		}()
+	}
+
 	// Setup HTTP server
-	server := &http.Server{
+	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", *port),
 		Handler: nil,
 	}
 
 	// Setup HTTP handler with custom registry
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	handler := promhttp.HandlerFor(exp.registry, promhttp.HandlerOpts{})
 	http.Handle("/metrics", handler)
+	http.Handle("/probe", server.ProbeHandler(exp.targets, cfg.GetErrorPolicies(), exp.denoms))
+	http.Handle("/api/v1/paths", server.PathsHandler(exp.targets, exp.status))
+	http.Handle("/api/v1/packets", server.PacketsHandler(exp.targets, exp.status, exp.packets))
+	http.Handle("/api/v1/routes", server.RoutesHandler(exp.targets, exp.status, exp.paths))
+	http.Handle("/status", server.StatusHandler(exp.targets, exp.status))
+	http.Handle("/healthz", server.HealthzHandler())
+	http.Handle("/readyz", server.ReadyzHandler(exp.health))
 
 	// Start server in a goroutine
 	go func() {
-		log.Info(fmt.Sprintf("Starting server on addr: %s", server.Addr))
+		log.Info(fmt.Sprintf("Starting server on addr: %s", httpServer.Addr))
 		log.Info(fmt.Sprintf("Configuration refresh interval: %s", refreshInterval.String()))
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatal(fmt.Sprintf("Server error: %v", err))
 		}
 	}()
@@ -188,7 +458,7 @@ func main() {
 	cancel()
 
 	// Shutdown the HTTP server
-	if err := server.Shutdown(shutdownCtx); err != nil {
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		log.Error(fmt.Sprintf("Server shutdown error: %v", err))
 	}
 
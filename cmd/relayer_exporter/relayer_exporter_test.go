@@ -2,8 +2,13 @@ package main
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
 )
 
 func TestGetVersion(t *testing.T) {
@@ -12,3 +17,36 @@ func TestGetVersion(t *testing.T) {
 
 	assert.Equal(t, exp, res)
 }
+
+func TestStatusGatherer(t *testing.T) {
+	gathered := 0
+	now := time.Unix(1700000000, 0)
+
+	g := newStatusGatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		gathered++
+		return nil, nil
+	}), time.Minute)
+	g.now = func() time.Time { return now }
+
+	// Notifier and remediator evaluating at the same time gather once.
+	for i := 0; i < 2; i++ {
+		_, err := g.Gather()
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, gathered)
+
+	now = now.Add(time.Minute)
+	_, err := g.Gather()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, gathered)
+}
+
+func TestStatusGatherInterval(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: &config.Notifications{Interval: 4 * time.Minute},
+		Remediation:   &config.Remediation{Interval: 2 * time.Minute},
+	}
+
+	assert.Equal(t, time.Minute, statusGatherInterval(cfg))
+}
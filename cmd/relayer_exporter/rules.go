@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/rules"
+)
+
+// runRules prints Prometheus alerting rules generated from alertRules
+// thresholds in config file.
+func runRules(args []string) int {
+	fs := flag.NewFlagSet("rules", flag.ExitOnError)
+	configPath := fs.String("config", "./config.yml", "path to config file")
+	format := fs.String("format", rules.FormatRules, "output format: rules or prometheusrule")
+	name := fs.String("name", "relayer-exporter", "PrometheusRule name")
+	namespace := fs.String("namespace", "", "PrometheusRule namespace")
+	output := fs.String("output", "", "write rules to file instead of stdout")
+
+	_ = fs.Parse(args)
+
+	cfg, err := config.NewConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *configPath, err)
+		return exitError
+	}
+
+	if cfg.AlertRules == nil {
+		fmt.Fprintf(os.Stderr, "%s: alertRules not configured\n", *configPath)
+		return exitError
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	meta := rules.ObjectMeta{Name: *name, Namespace: *namespace}
+	if err := rules.Write(w, rules.Generate(cfg.AlertRules), *format, meta); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// runValidate lints config file and optionally a local directory of IBC
+// registry files offline. Every problem found is printed and the command
+// fails if there is any.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := fs.String("config", "./config.yml", "path to config file")
+	pathsDir := fs.String("paths-dir", "", "local IBC registry directory to validate")
+
+	_ = fs.Parse(args)
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *configPath, err)
+		return exitError
+	}
+
+	errs := []error{}
+	for _, err := range cfg.ValidationErrors() {
+		errs = append(errs, fmt.Errorf("%s: %w", *configPath, err))
+	}
+
+	if *pathsDir != "" {
+		errs = append(errs, config.LintIBCPaths(*pathsDir, cfg.GetRPCsMap())...)
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "found %d problem(s)\n", len(errs))
+		return exitInvalid
+	}
+
+	fmt.Println("no problems found")
+
+	return exitOK
+}
@@ -2,6 +2,14 @@ package chain
 
 import (
 	"context"
+	"fmt"
+
+	"cosmossdk.io/math"
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
 
 	log "github.com/archway-network/relayer_exporter/pkg/logger"
 	"github.com/cosmos/relayer/v2/relayer"
@@ -9,8 +17,8 @@ import (
 )
 
 const (
-	rpcTimeout     = "10s"
-	keyringBackend = "test"
+	rpcTimeout            = "10s"
+	defaultKeyringBackend = "test"
 )
 
 type Info struct {
@@ -18,6 +26,22 @@ type Info struct {
 	RPCAddr  string
 	ClientID string
 	Timeout  string
+	// Signer is needed only to send transactions.
+	Signer *Signer
+}
+
+// Signer configures key used to sign transactions on a chain.
+type Signer struct {
+	// Key name in keyring.
+	Key string
+	// KeyringBackend defaults to test if empty.
+	KeyringBackend string
+	// Home is relayer home directory, keys of a chain are kept in
+	// <home>/keys/<chain-id>.
+	Home          string
+	AccountPrefix string
+	GasPrices     string
+	GasAdjustment float64
 }
 
 func PrepChain(ctx context.Context, info Info) (*relayer.Chain, error) {
@@ -29,11 +53,25 @@ func PrepChain(ctx context.Context, info Info) (*relayer.Chain, error) {
 	providerConfig := cosmos.CosmosProviderConfig{
 		ChainID:        info.ChainID,
 		Timeout:        timeout,
-		KeyringBackend: keyringBackend,
+		KeyringBackend: defaultKeyringBackend,
 		RPCAddr:        info.RPCAddr,
 	}
 
-	provider, err := providerConfig.NewProvider(nil, "", false, info.ChainID)
+	home := ""
+
+	if s := info.Signer; s != nil {
+		providerConfig.Key = s.Key
+		providerConfig.AccountPrefix = s.AccountPrefix
+		providerConfig.GasPrices = s.GasPrices
+		providerConfig.GasAdjustment = s.GasAdjustment
+		home = s.Home
+
+		if s.KeyringBackend != "" {
+			providerConfig.KeyringBackend = s.KeyringBackend
+		}
+	}
+
+	provider, err := providerConfig.NewProvider(log.GetLogger(), home, false, info.ChainID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,3 +90,74 @@ func PrepChain(ctx context.Context, info Info) (*relayer.Chain, error) {
 
 	return chain, nil
 }
+
+// CheckRPC verifies that chain RPC endpoint is reachable by querying its
+// latest height.
+func CheckRPC(ctx context.Context, info Info) error {
+	chain, err := PrepChain(ctx, info)
+	if err != nil {
+		return err
+	}
+
+	_, err = chain.ChainProvider.QueryLatestHeight(ctx)
+
+	return err
+}
+
+// TxSearch searches transactions matching query ordered by height, "asc" or
+// "desc".
+func TxSearch(
+	ctx context.Context, chain *relayer.Chain, query string, page, perPage int, order string,
+) (*coretypes.ResultTxSearch, error) {
+	provider, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("transaction search not supported by %s provider", chain.ChainProvider.Type())
+	}
+
+	return provider.RPCClient.TxSearch(ctx, query, false, &page, &perPage, order)
+}
+
+// QueryIBCStore returns value of key in IBC store of chain at the latest
+// height, empty if the key is not set.
+func QueryIBCStore(ctx context.Context, chain *relayer.Chain, key []byte) ([]byte, error) {
+	provider, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("store query not supported by %s provider", chain.ChainProvider.Type())
+	}
+
+	res, err := provider.QueryABCI(ctx, abci.RequestQuery{
+		Path: fmt.Sprintf("store/%s/key", ibcexported.StoreKey),
+		Data: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Value, nil
+}
+
+// ClientConn returns connection running gRPC queries on chain through its
+// RPC endpoint.
+func ClientConn(chain *relayer.Chain) (gogogrpc.ClientConn, error) {
+	provider, ok := chain.ChainProvider.(*cosmos.CosmosProvider)
+	if !ok {
+		return nil, fmt.Errorf("gRPC queries not supported by %s provider", chain.ChainProvider.Type())
+	}
+
+	return provider, nil
+}
+
+// QuerySupplyOf returns total supply of denom on chain.
+func QuerySupplyOf(ctx context.Context, chain *relayer.Chain, denom string) (math.Int, error) {
+	conn, err := ClientConn(chain)
+	if err != nil {
+		return math.Int{}, err
+	}
+
+	res, err := banktypes.NewQueryClient(conn).SupplyOf(ctx, &banktypes.QuerySupplyOfRequest{Denom: denom})
+	if err != nil {
+		return math.Int{}, err
+	}
+
+	return res.Amount.Amount, nil
+}
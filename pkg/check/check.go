@@ -0,0 +1,276 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// Thresholds define when a client or channel check fails.
+type Thresholds struct {
+	// ClientExpiry fails clients expiring sooner than this duration.
+	ClientExpiry time.Duration
+	// StuckPackets fails channel ends with more stuck packets than this.
+	StuckPackets int
+}
+
+type ClientReport struct {
+	Path                  string     `json:"path"`
+	ChainName             string     `json:"chain_name"`
+	ChainID               string     `json:"chain_id"`
+	ClientID              string     `json:"client_id"`
+	CounterpartyChainName string     `json:"counterparty_chain_name"`
+	Expiry                *time.Time `json:"expiry"`
+	SecondsToExpiry       *float64   `json:"seconds_to_expiry"`
+	Failed                bool       `json:"failed"`
+}
+
+type ChannelReport struct {
+	Path                  string `json:"path"`
+	ChainName             string `json:"chain_name"`
+	ChannelID             string `json:"channel_id"`
+	PortID                string `json:"port_id"`
+	CounterpartyChainName string `json:"counterparty_chain_name"`
+	CounterpartyChannelID string `json:"counterparty_channel_id"`
+	StuckPackets          int    `json:"stuck_packets"`
+	Failed                bool   `json:"failed"`
+}
+
+type PathError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Report is the result of a single run of client and channel checks.
+type Report struct {
+	Clients  []ClientReport  `json:"clients"`
+	Channels []ChannelReport `json:"channels"`
+	Errors   []PathError     `json:"errors"`
+}
+
+// Run queries all paths concurrently and returns report of their clients
+// and channels.
+func Run(
+	ctx context.Context, paths []*config.IBCData, rpcs *map[string]config.RPC, thresholds Thresholds,
+) Report {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	statuses := []collector.PathStatus{}
+
+	for _, p := range paths {
+		wg.Add(1)
+
+		go func(path *config.IBCData) {
+			defer wg.Done()
+
+			status := collector.GetPathStatus(ctx, path, rpcs, nil)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			statuses = append(statuses, status)
+		}(p)
+	}
+
+	wg.Wait()
+
+	return NewReport(statuses, thresholds, time.Now())
+}
+
+// NewReport evaluates thresholds against path statuses. Clients are sorted
+// by time to expiry, only channel ends with stuck packets are reported.
+func NewReport(statuses []collector.PathStatus, thresholds Thresholds, now time.Time) Report {
+	report := Report{
+		Clients:  []ClientReport{},
+		Channels: []ChannelReport{},
+		Errors:   []PathError{},
+	}
+
+	for _, s := range statuses {
+		if s.LastError != "" {
+			report.Errors = append(report.Errors, PathError{Path: s.Name, Error: s.LastError})
+		}
+
+		for _, c := range [][2]collector.ChainStatus{{s.Chain1, s.Chain2}, {s.Chain2, s.Chain1}} {
+			src, dst := c[0], c[1]
+
+			client := ClientReport{
+				Path:                  s.Name,
+				ChainName:             src.ChainName,
+				ChainID:               src.ChainID,
+				ClientID:              src.ClientID,
+				CounterpartyChainName: dst.ChainName,
+				Expiry:                src.ClientExpiry,
+			}
+
+			// Clients with unknown expiry failed to query and can't be
+			// trusted to pass.
+			client.Failed = true
+
+			if src.ClientExpiry != nil {
+				left := src.ClientExpiry.Sub(now)
+				seconds := left.Seconds()
+				client.SecondsToExpiry = &seconds
+				client.Failed = left < thresholds.ClientExpiry
+			}
+
+			report.Clients = append(report.Clients, client)
+		}
+
+		for _, c := range s.Channels {
+			if c.SrcStuckPackets > 0 {
+				report.Channels = append(report.Channels, ChannelReport{
+					Path:                  s.Name,
+					ChainName:             s.Chain1.ChainName,
+					ChannelID:             c.SrcChannelID,
+					PortID:                c.SrcPortID,
+					CounterpartyChainName: s.Chain2.ChainName,
+					CounterpartyChannelID: c.DstChannelID,
+					StuckPackets:          c.SrcStuckPackets,
+					Failed:                c.SrcStuckPackets > thresholds.StuckPackets,
+				})
+			}
+
+			if c.DstStuckPackets > 0 {
+				report.Channels = append(report.Channels, ChannelReport{
+					Path:                  s.Name,
+					ChainName:             s.Chain2.ChainName,
+					ChannelID:             c.DstChannelID,
+					PortID:                c.DstPortID,
+					CounterpartyChainName: s.Chain1.ChainName,
+					CounterpartyChannelID: c.SrcChannelID,
+					StuckPackets:          c.DstStuckPackets,
+					Failed:                c.DstStuckPackets > thresholds.StuckPackets,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(report.Clients, func(i, j int) bool {
+		a, b := report.Clients[i].Expiry, report.Clients[j].Expiry
+		if a == nil || b == nil {
+			return a != nil
+		}
+
+		return a.Before(*b)
+	})
+
+	sort.SliceStable(report.Channels, func(i, j int) bool {
+		return report.Channels[i].StuckPackets > report.Channels[j].StuckPackets
+	})
+
+	sort.Slice(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Path < report.Errors[j].Path
+	})
+
+	return report
+}
+
+// Errored returns true if queries of any path failed.
+func (r Report) Errored() bool {
+	return len(r.Errors) > 0
+}
+
+// Failed returns true if any client or channel breached thresholds or
+// failed to query.
+func (r Report) Failed() bool {
+	if r.Errored() {
+		return true
+	}
+
+	for _, c := range r.Clients {
+		if c.Failed {
+			return true
+		}
+	}
+
+	for _, c := range r.Channels {
+		if c.Failed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Write writes report to w in table or json format.
+func (r Report) Write(w io.Writer, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r)
+	case OutputTable:
+		return r.writeTable(w)
+	default:
+		return fmt.Errorf("unknown output format: %s", output)
+	}
+}
+
+func (r Report) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "PATH\tCHAIN\tCLIENT\tCOUNTERPARTY\tEXPIRY\tTIME TO EXPIRY\tSTATUS")
+
+	for _, c := range r.Clients {
+		expiry, left := "unknown", "unknown"
+		if c.Expiry != nil {
+			expiry = c.Expiry.UTC().Format(time.RFC3339)
+			left = (time.Duration(*c.SecondsToExpiry) * time.Second).Truncate(time.Minute).String()
+		}
+
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Path, c.ChainName, c.ClientID, c.CounterpartyChainName, expiry, left, status(c.Failed),
+		)
+	}
+
+	if len(r.Channels) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "PATH\tCHAIN\tCHANNEL\tPORT\tCOUNTERPARTY\tCOUNTERPARTY CHANNEL\tSTUCK PACKETS\tSTATUS")
+
+		for _, c := range r.Channels {
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				c.Path, c.ChainName, c.ChannelID, c.PortID, c.CounterpartyChainName, c.CounterpartyChannelID,
+				c.StuckPackets, status(c.Failed),
+			)
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "PATH\tERROR")
+
+		for _, e := range r.Errors {
+			fmt.Fprintf(tw, "%s\t%s\n", e.Path, e.Error)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func status(failed bool) string {
+	if failed {
+		return "FAIL"
+	}
+
+	return "OK"
+}
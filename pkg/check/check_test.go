@@ -0,0 +1,163 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+)
+
+func testStatuses(now time.Time) []collector.PathStatus {
+	soon := now.Add(24 * time.Hour)
+	later := now.Add(10 * 24 * time.Hour)
+
+	return []collector.PathStatus{
+		{
+			Name:   "archway-osmosis",
+			Chain1: collector.ChainStatus{ChainName: "archway", ClientID: "07-tendermint-1", ClientExpiry: &later},
+			Chain2: collector.ChainStatus{ChainName: "osmosis", ClientID: "07-tendermint-2", ClientExpiry: &soon},
+			Channels: []collector.ChannelStatus{
+				{SrcChannelID: "channel-1", DstChannelID: "channel-2", SrcStuckPackets: 0, DstStuckPackets: 4},
+			},
+		},
+		{
+			Name:      "archway-noble",
+			Chain1:    collector.ChainStatus{ChainName: "archway", ClientID: "07-tendermint-3"},
+			Chain2:    collector.ChainStatus{ChainName: "noble", ClientID: "07-tendermint-4"},
+			LastError: "rpc error",
+		},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name       string
+		thresholds Thresholds
+		failed     bool
+	}{
+		{
+			name:       "Client Expiry Breached",
+			thresholds: Thresholds{ClientExpiry: 72 * time.Hour, StuckPackets: 10},
+			failed:     true,
+		},
+		{
+			name:       "Stuck Packets Breached",
+			thresholds: Thresholds{ClientExpiry: time.Hour, StuckPackets: 0},
+			failed:     true,
+		},
+		{
+			name:       "No Threshold Breached",
+			thresholds: Thresholds{ClientExpiry: time.Hour, StuckPackets: 10},
+			failed:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := NewReport(testStatuses(now), tc.thresholds, now)
+
+			clients := []string{}
+			for _, c := range report.Clients {
+				clients = append(clients, c.ClientID)
+			}
+
+			assert.Equal(t, []string{"07-tendermint-2", "07-tendermint-1", "07-tendermint-3", "07-tendermint-4"}, clients)
+			assert.Len(t, report.Channels, 1)
+			assert.Equal(t, "osmosis", report.Channels[0].ChainName)
+			assert.Equal(t, []PathError{{Path: "archway-noble", Error: "rpc error"}}, report.Errors)
+			assert.True(t, report.Failed())
+
+			// Without the path failed to query only thresholds matter.
+			report = NewReport(testStatuses(now)[:1], tc.thresholds, now)
+			assert.False(t, report.Errored())
+			assert.Equal(t, tc.failed, report.Failed())
+		})
+	}
+}
+
+func TestNewReportAllQueriesFailed(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	statuses := []collector.PathStatus{
+		{
+			Name:      "archway-osmosis",
+			Chain1:    collector.ChainStatus{ChainName: "archway", ClientID: "07-tendermint-1"},
+			Chain2:    collector.ChainStatus{ChainName: "osmosis", ClientID: "07-tendermint-2"},
+			LastError: "connection refused",
+		},
+		{
+			Name:      "archway-noble",
+			Chain1:    collector.ChainStatus{ChainName: "archway", ClientID: "07-tendermint-3"},
+			Chain2:    collector.ChainStatus{ChainName: "noble", ClientID: "07-tendermint-4"},
+			LastError: "connection refused",
+		},
+	}
+
+	report := NewReport(statuses, Thresholds{ClientExpiry: time.Hour, StuckPackets: 10}, now)
+
+	assert.Len(t, report.Errors, 2)
+	assert.True(t, report.Errored())
+	assert.True(t, report.Failed())
+
+	for _, c := range report.Clients {
+		assert.Nil(t, c.Expiry)
+		assert.True(t, c.Failed)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	report := NewReport(testStatuses(now), Thresholds{ClientExpiry: 72 * time.Hour}, now)
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, report.Write(&buf, OutputTable))
+	assert.Contains(t, buf.String(), "07-tendermint-2")
+	assert.Contains(t, buf.String(), "FAIL")
+
+	buf.Reset()
+	assert.NoError(t, report.Write(&buf, OutputJSON))
+
+	res := Report{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+	assert.Len(t, res.Clients, 4)
+
+	assert.Error(t, report.Write(&buf, "xml"))
+}
+
+func TestWritePackets(t *testing.T) {
+	timeout := time.Unix(1700000000, 0).UTC()
+	packets := []collector.ChannelPackets{
+		{
+			Path:      "archway-osmosis",
+			ChainName: "archway",
+			ChannelID: "channel-1",
+			Packets: []ibc.Packet{
+				{Sequence: 5, TimeoutTimestamp: &timeout, Sender: "archway1abc", Receiver: "osmo1abc", Denom: "aarch", Amount: "100"},
+				{Sequence: 6, Error: "tx not found"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, WritePackets(&buf, packets, OutputTable))
+	assert.Contains(t, buf.String(), "2023-11-14T22:13:20Z")
+	assert.Contains(t, buf.String(), "100aarch")
+	assert.Contains(t, buf.String(), "tx not found")
+
+	buf.Reset()
+	assert.NoError(t, WritePackets(&buf, packets, OutputJSON))
+
+	res := []collector.ChannelPackets{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+	assert.Equal(t, packets, res)
+
+	assert.Error(t, WritePackets(&buf, packets, "xml"))
+}
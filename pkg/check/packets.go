@@ -0,0 +1,49 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+)
+
+// WritePackets writes stuck packets to w in table or json format.
+func WritePackets(w io.Writer, packets []collector.ChannelPackets, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(packets)
+	case OutputTable:
+		return writePacketsTable(w, packets)
+	default:
+		return fmt.Errorf("unknown output format: %s", output)
+	}
+}
+
+func writePacketsTable(w io.Writer, packets []collector.ChannelPackets) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CHAIN\tCHANNEL\tSEQUENCE\tTIMEOUT HEIGHT\tTIMEOUT\tSENDER\tRECEIVER\tAMOUNT\tERROR")
+
+	for _, c := range packets {
+		for _, p := range c.Packets {
+			timeout := ""
+			if p.TimeoutTimestamp != nil {
+				timeout = p.TimeoutTimestamp.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(
+				tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.ChainName, c.ChannelID, p.Sequence, p.TimeoutHeight, timeout, p.Sender, p.Receiver,
+				p.Amount+p.Denom, p.Error,
+			)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/store"
+)
+
+const balanceHistoryBucket = "balance_history"
+
+// BalanceSample is a single successfully collected account balance.
+type BalanceSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Balance   float64   `json:"balance"`
+}
+
+// BalanceHistory keeps balance samples of each account within a rolling
+// window to estimate how fast accounts spend funds. It outlives collectors
+// and is persisted to store so estimates survive restarts.
+type BalanceHistory struct {
+	mu      sync.Mutex
+	window  time.Duration
+	store   *store.Store
+	samples map[string][]BalanceSample
+}
+
+// NewBalanceHistory returns history keeping samples for window loaded from
+// s, which may be nil.
+func NewBalanceHistory(window time.Duration, s *store.Store) (*BalanceHistory, error) {
+	h := &BalanceHistory{
+		window:  window,
+		store:   s,
+		samples: map[string][]BalanceSample{},
+	}
+
+	err := s.ForEach(balanceHistoryBucket, func(key string, value []byte) error {
+		samples := []BalanceSample{}
+		if err := json.Unmarshal(value, &samples); err != nil {
+			return err
+		}
+
+		h.samples[key] = samples
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Add records balance of account with key at time ts and drops samples
+// older than the window.
+func (h *BalanceHistory) Add(key string, ts time.Time, balance float64) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := append(h.samples[key], BalanceSample{Timestamp: ts, Balance: balance})
+
+	start := 0
+	for start < len(samples) && ts.Sub(samples[start].Timestamp) > h.window {
+		start++
+	}
+
+	h.samples[key] = samples[start:]
+}
+
+// BurnRate returns spend rate of account with key per second. Only balance
+// decreases are counted, so top-ups don't hide spending. False is returned
+// until samples span some time.
+func (h *BalanceHistory) BurnRate(key string) (float64, bool) {
+	if h == nil {
+		return 0, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return burnRate(h.samples[key])
+}
+
+func burnRate(samples []BalanceSample) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	elapsed := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+	if elapsed <= 0 {
+		return 0, false
+	}
+
+	spent := 0.0
+
+	for i := 1; i < len(samples); i++ {
+		if diff := samples[i-1].Balance - samples[i].Balance; diff > 0 {
+			spent += diff
+		}
+	}
+
+	return spent / elapsed.Seconds(), true
+}
+
+// Runway returns seconds until balance drops to minBalance at burn rate.
+// False is returned when nothing is being spent.
+func Runway(balance, minBalance, rate float64) (float64, bool) {
+	if rate <= 0 {
+		return 0, false
+	}
+
+	if balance <= minBalance {
+		return 0, true
+	}
+
+	return (balance - minBalance) / rate, true
+}
+
+// Save writes samples to store.
+func (h *BalanceHistory) Save() error {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values := map[string]any{}
+	for key, samples := range h.samples {
+		values[key] = samples
+	}
+
+	return h.store.PutAll(balanceHistoryBucket, values)
+}
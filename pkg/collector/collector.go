@@ -2,7 +2,6 @@ package collector
 
 import (
 	"context"
-	"regexp"
 	"strings"
 
 	"github.com/archway-network/relayer_exporter/pkg/config"
@@ -15,13 +14,21 @@ const (
 
 var ctx = context.Background()
 
+// collectContext returns c or, if it is nil, the default context of
+// collections, which has no deadline.
+func collectContext(c context.Context) context.Context {
+	if c == nil {
+		return ctx
+	}
+
+	return c
+}
+
 func getDiscordIDs(ops []config.Operator) string {
 	var ids []string
 
-	pattern := regexp.MustCompile(`^\d+$`)
-
 	for _, op := range ops {
-		if pattern.MatchString(op.Discord.ID) {
+		if config.DiscordIDPattern.MatchString(op.Discord.ID) {
 			ids = append(ids, op.Discord.ID)
 		}
 	}
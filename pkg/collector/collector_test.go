@@ -1,11 +1,25 @@
 package collector
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"cosmossdk.io/math"
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+	"github.com/archway-network/relayer_exporter/pkg/store"
 )
 
 func TestDiscordIDs(t *testing.T) {
@@ -62,3 +76,1220 @@ func TestDiscordIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestSampleCacheCollect(t *testing.T) {
+	desc := prometheus.NewDesc("test_metric", "", []string{"id", "status"}, nil)
+	lastSuccessDesc := prometheus.NewDesc("test_metric_last_success_timestamp", "", []string{"id"}, nil)
+	now := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name     string
+		policy   config.ErrorPolicy
+		cached   bool
+		failed   bool
+		expected map[string]float64
+	}{
+		{
+			name:   "Success",
+			policy: config.ErrorPolicyOmit,
+			failed: false,
+			expected: map[string]float64{
+				"test_metric":                        42,
+				"test_metric_last_success_timestamp": 1700000000,
+			},
+		},
+		{
+			name:     "Error Without Cached Value",
+			policy:   config.ErrorPolicyLastSuccess,
+			failed:   true,
+			expected: map[string]float64{},
+		},
+		{
+			name:   "Error With Omit Policy",
+			policy: config.ErrorPolicyOmit,
+			cached: true,
+			failed: true,
+			expected: map[string]float64{
+				"test_metric_last_success_timestamp": 1700000000,
+			},
+		},
+		{
+			name:   "Error With Last Success Policy",
+			policy: config.ErrorPolicyLastSuccess,
+			cached: true,
+			failed: true,
+			expected: map[string]float64{
+				"test_metric":                        7,
+				"test_metric_last_success_timestamp": 1700000000,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewSampleCache()
+			cache.now = func() time.Time { return now }
+
+			if tc.cached {
+				cache.store(desc, []string{"a"}, 7)
+			}
+
+			ch := make(chan prometheus.Metric, 2)
+			cache.collect(ch, desc, lastSuccessDesc, tc.policy, 42, tc.failed, []string{"a"})
+			close(ch)
+
+			res := map[string]float64{}
+
+			for m := range ch {
+				metric := &dto.Metric{}
+				assert.NoError(t, m.Write(metric))
+
+				if m.Desc() == desc {
+					expStatus := successStatus
+					if tc.failed {
+						expStatus = errorStatus
+					}
+
+					assert.Contains(t, metric.GetLabel(), &dto.LabelPair{
+						Name: stringPtr("status"), Value: &expStatus,
+					})
+
+					res["test_metric"] = metric.GetGauge().GetValue()
+				} else {
+					res["test_metric_last_success_timestamp"] = metric.GetGauge().GetValue()
+				}
+			}
+
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestNilSampleCacheCollect(t *testing.T) {
+	desc := prometheus.NewDesc("test_metric", "", []string{"id", "status"}, nil)
+	lastSuccessDesc := prometheus.NewDesc("test_metric_last_success_timestamp", "", []string{"id"}, nil)
+
+	var cache *SampleCache
+
+	ch := make(chan prometheus.Metric, 2)
+	cache.collect(ch, desc, lastSuccessDesc, config.ErrorPolicyLastSuccess, 42, false, []string{"a"})
+	assert.Len(t, ch, 2)
+
+	// Nothing was kept, the failed series is omitted.
+	ch = make(chan prometheus.Metric, 2)
+	cache.collect(ch, desc, lastSuccessDesc, config.ErrorPolicyLastSuccess, 0, true, []string{"a"})
+	assert.Empty(t, ch)
+}
+
+func TestSampleCacheCollectStates(t *testing.T) {
+	desc := prometheus.NewDesc("test_metric", "", []string{"id", "state", "status"}, nil)
+	lastSuccessDesc := prometheus.NewDesc("test_metric_last_success_timestamp", "", []string{"id"}, nil)
+	states := []string{"relayable", "timed_out"}
+	values := map[string]float64{"relayable": 3, "timed_out": 2}
+
+	testCases := []struct {
+		name     string
+		policy   config.ErrorPolicy
+		failed   bool
+		expected map[string]float64
+	}{
+		{
+			name:   "Success",
+			policy: config.ErrorPolicyOmit,
+			expected: map[string]float64{
+				"relayable":    3,
+				"timed_out":    2,
+				"last_success": 1700000000,
+			},
+		},
+		{
+			name:   "Error With Omit Policy",
+			policy: config.ErrorPolicyOmit,
+			failed: true,
+			expected: map[string]float64{
+				"last_success": 1700000000,
+			},
+		},
+		{
+			name:   "Error With Last Success Policy",
+			policy: config.ErrorPolicyLastSuccess,
+			failed: true,
+			expected: map[string]float64{
+				"relayable":    1,
+				"timed_out":    1,
+				"last_success": 1700000000,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewSampleCache()
+			cache.now = func() time.Time { return time.Unix(1700000000, 0) }
+			cache.store(desc, []string{"a", "relayable"}, 1)
+			cache.store(desc, []string{"a", "timed_out"}, 1)
+
+			ch := make(chan prometheus.Metric, 3)
+			cache.collectStates(ch, desc, lastSuccessDesc, tc.policy, states, values, tc.failed, []string{"a"})
+			close(ch)
+
+			res := map[string]float64{}
+
+			for m := range ch {
+				metric := &dto.Metric{}
+				assert.NoError(t, m.Write(metric))
+
+				if m.Desc() != desc {
+					res["last_success"] = metric.GetGauge().GetValue()
+					continue
+				}
+
+				for _, l := range metric.GetLabel() {
+					if l.GetName() == "state" {
+						res[l.GetValue()] = metric.GetGauge().GetValue()
+					}
+				}
+			}
+
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestStatusStoreUpdate(t *testing.T) {
+	expiry := time.Unix(1700000000, 0)
+	store := NewStatusStore()
+
+	store.UpdatePath(PathStatus{
+		Name:     "archway-osmosis",
+		Chain1:   ChainStatus{ClientExpiry: &expiry, ClientLastUpdate: &expiry, ClientUpdateInterval: time.Hour},
+		Chain2:   ChainStatus{ClientExpiry: &expiry},
+		Channels: []ChannelStatus{{SrcChannelID: "channel-1", SrcStuckPackets: 3}},
+	})
+
+	store.UpdatePath(PathStatus{
+		Name:        "archway-osmosis",
+		Channels:    []ChannelStatus{{SrcChannelID: "channel-1"}},
+		LastError:   "rpc error",
+		clientsErr:  errors.New("rpc error"),
+		channelsErr: errors.New("rpc error"),
+	})
+
+	res, ok := store.Path("archway-osmosis")
+	assert.True(t, ok)
+	assert.Equal(t, &expiry, res.Chain1.ClientExpiry)
+	assert.Equal(t, &expiry, res.Chain2.ClientExpiry)
+	assert.Equal(t, &expiry, res.Chain1.ClientLastUpdate)
+	assert.Equal(t, time.Hour, res.Chain1.ClientUpdateInterval)
+	assert.Equal(t, 3, res.StuckPackets())
+	assert.Equal(t, "rpc error", res.LastError)
+
+	// Paths removed from the registry are pruned.
+	store.UpdatePath(PathStatus{Name: "archway-noble"})
+	store.RetainPaths([]string{"archway-noble"})
+
+	_, ok = store.Path("archway-osmosis")
+	assert.False(t, ok)
+	assert.Len(t, store.Paths(), 1)
+}
+
+func TestSetClientUpdate(t *testing.T) {
+	consensus := time.Unix(1700000000, 0)
+	event := time.Unix(1700000060, 0)
+
+	testCases := []struct {
+		name             string
+		update           ibc.ClientUpdate
+		expectedUpdate   *time.Time
+		expectedInterval time.Duration
+	}{
+		{
+			name:   "Unknown",
+			update: ibc.ClientUpdate{},
+		},
+		{
+			name:           "Consensus State Only",
+			update:         ibc.ClientUpdate{ConsensusTimestamp: consensus},
+			expectedUpdate: &consensus,
+		},
+		{
+			name:             "Update Events",
+			update:           ibc.ClientUpdate{ConsensusTimestamp: consensus, LastUpdate: event, Interval: time.Hour},
+			expectedUpdate:   &event,
+			expectedInterval: time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := ChainStatus{}
+			status.setClientUpdate(tc.update)
+
+			assert.Equal(t, tc.expectedUpdate, status.ClientLastUpdate)
+			assert.Equal(t, tc.expectedInterval, status.ClientUpdateInterval)
+		})
+	}
+}
+
+func TestMinClientExpiry(t *testing.T) {
+	earlier := time.Unix(1700000000, 0)
+	later := time.Unix(1800000000, 0)
+
+	testCases := []struct {
+		name     string
+		chain1   *time.Time
+		chain2   *time.Time
+		expected *time.Time
+	}{
+		{name: "Both Known", chain1: &later, chain2: &earlier, expected: &earlier},
+		{name: "Chain1 Unknown", chain1: nil, chain2: &later, expected: &later},
+		{name: "Chain2 Unknown", chain1: &earlier, chain2: nil, expected: &earlier},
+		{name: "Both Unknown", chain1: nil, chain2: nil, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := PathStatus{
+				Chain1: ChainStatus{ClientExpiry: tc.chain1},
+				Chain2: ChainStatus{ClientExpiry: tc.chain2},
+			}
+			assert.Equal(t, tc.expected, status.MinClientExpiry())
+		})
+	}
+}
+
+func TestNewPathStatusClientExpiryThreshold(t *testing.T) {
+	rpcs := &map[string]config.RPC{
+		"archway": {ChainName: "archway", ClientExpiryThreshold: 72 * time.Hour},
+		"osmosis": {ChainName: "osmosis"},
+	}
+
+	testCases := []struct {
+		name           string
+		pathThreshold  time.Duration
+		expectedChain1 time.Duration
+		expectedChain2 time.Duration
+	}{
+		{name: "Chain Thresholds", expectedChain1: 72 * time.Hour, expectedChain2: 0},
+		{
+			name:           "Path Threshold Overrides Chain",
+			pathThreshold:  168 * time.Hour,
+			expectedChain1: 168 * time.Hour,
+			expectedChain2: 168 * time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := &config.IBCData{
+				Chain1:                config.IBCChainMeta{ChainName: "archway"},
+				Chain2:                config.IBCChainMeta{ChainName: "osmosis"},
+				ClientExpiryThreshold: tc.pathThreshold,
+			}
+
+			status := NewPathStatus(path, rpcs)
+			assert.Equal(t, tc.expectedChain1, status.Chain1.ClientExpiryThreshold)
+			assert.Equal(t, tc.expectedChain2, status.Chain2.ClientExpiryThreshold)
+		})
+	}
+}
+
+func TestBalanceHistoryBurnRate(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name     string
+		balances []float64
+		rate     float64
+		ok       bool
+	}{
+		{name: "No Samples", balances: []float64{}, ok: false},
+		{name: "Single Sample", balances: []float64{100}, ok: false},
+		{name: "Steady Spending", balances: []float64{100, 90, 80, 70}, rate: 10.0 / 60, ok: true},
+		{name: "Top-Up Excluded", balances: []float64{100, 90, 1000, 990}, rate: 20.0 / 180, ok: true},
+		{name: "Nothing Spent", balances: []float64{100, 100, 150}, rate: 0, ok: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewBalanceHistory(24*time.Hour, nil)
+			assert.NoError(t, err)
+
+			for i, b := range tc.balances {
+				h.Add("a", start.Add(time.Duration(i)*time.Minute), b)
+			}
+
+			rate, ok := h.BurnRate("a")
+			assert.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.rate, rate, 1e-9)
+		})
+	}
+}
+
+func TestBalanceHistoryWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	h, err := NewBalanceHistory(time.Hour, nil)
+	assert.NoError(t, err)
+
+	// Spending before the window must not affect the rate.
+	h.Add("a", start, 1000)
+	h.Add("a", start.Add(30*time.Minute), 500)
+	h.Add("a", start.Add(90*time.Minute), 490)
+	h.Add("a", start.Add(120*time.Minute), 480)
+
+	rate, ok := h.BurnRate("a")
+	assert.True(t, ok)
+	assert.InDelta(t, 10.0/1800, rate, 1e-9)
+}
+
+func TestRunway(t *testing.T) {
+	testCases := []struct {
+		name       string
+		balance    float64
+		minBalance float64
+		rate       float64
+		runway     float64
+		ok         bool
+	}{
+		{name: "Nothing Spent", balance: 100, rate: 0, ok: false},
+		{name: "Without Threshold", balance: 100, rate: 2, runway: 50, ok: true},
+		{name: "With Threshold", balance: 100, minBalance: 40, rate: 2, runway: 30, ok: true},
+		{name: "Below Threshold", balance: 30, minBalance: 40, rate: 2, runway: 0, ok: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runway, ok := Runway(tc.balance, tc.minBalance, tc.rate)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.runway, runway)
+		})
+	}
+}
+
+func testStore(t *testing.T) *store.Store {
+	db, err := store.Open(filepath.Join(t.TempDir(), "relayer_exporter.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestBalanceHistoryPersistence(t *testing.T) {
+	db := testStore(t)
+	start := time.Unix(1700000000, 0)
+
+	h, err := NewBalanceHistory(24*time.Hour, db)
+	assert.NoError(t, err)
+
+	h.Add("a", start, 100)
+	h.Add("a", start.Add(time.Minute), 40)
+	assert.NoError(t, h.Save())
+
+	loaded, err := NewBalanceHistory(24*time.Hour, db)
+	assert.NoError(t, err)
+
+	rate, ok := loaded.BurnRate("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, rate)
+}
+
+func testEvent(eventType string, attrs ...string) abci.Event {
+	e := abci.Event{Type: eventType}
+	for i := 0; i < len(attrs); i += 2 {
+		e.Attributes = append(e.Attributes, abci.EventAttribute{Key: attrs[i], Value: attrs[i+1]})
+	}
+
+	return e
+}
+
+func TestParseFeeTx(t *testing.T) {
+	path := &config.IBCData{
+		Chain1: config.IBCChainMeta{ChainName: "archway", ClientID: "07-tendermint-1"},
+		Chain2: config.IBCChainMeta{ChainName: "osmosis", ClientID: "07-tendermint-2"},
+	}
+	path.Channels = make([]config.Channel, 1)
+	path.Channels[0].Chain1.ChannelID = "channel-1"
+	path.Channels[0].Chain2.ChannelID = "channel-2"
+
+	index := newPathIndex([]*config.IBCData{path})
+
+	testCases := []struct {
+		name     string
+		events   []abci.Event
+		expected FeeTx
+	}{
+		{
+			name: "Update Client And Recv Packet",
+			events: []abci.Event{
+				testEvent("tx", "fee", "1000aarch", "fee_payer", "archway1abc"),
+				testEvent("update_client", "client_id", "07-tendermint-1"),
+				testEvent("recv_packet", "packet_src_channel", "channel-2", "packet_dst_channel", "channel-1"),
+			},
+			expected: FeeTx{
+				Height:   10,
+				Fees:     sdk.NewCoins(sdk.NewInt64Coin("aarch", 1000)),
+				Path:     "archway-osmosis",
+				MsgTypes: []string{"MsgUpdateClient", "MsgRecvPacket"},
+			},
+		},
+		{
+			name: "Acknowledgement",
+			events: []abci.Event{
+				testEvent("tx", "fee", "5aarch"),
+				testEvent("acknowledge_packet", "packet_src_channel", "channel-1", "packet_dst_channel", "channel-2"),
+			},
+			expected: FeeTx{
+				Height:   10,
+				Fees:     sdk.NewCoins(sdk.NewInt64Coin("aarch", 5)),
+				Path:     "archway-osmosis",
+				MsgTypes: []string{"MsgAcknowledgement"},
+			},
+		},
+		{
+			name: "Counterparty Channel Not Matched",
+			events: []abci.Event{
+				testEvent("timeout_packet", "packet_src_channel", "channel-2"),
+			},
+			expected: FeeTx{Height: 10, Fees: sdk.Coins{}, MsgTypes: []string{"MsgTimeout"}},
+		},
+		{
+			name: "Non IBC Transaction",
+			events: []abci.Event{
+				testEvent("tx", "fee", "7aarch"),
+				testEvent("transfer", "amount", "100aarch"),
+			},
+			expected: FeeTx{Height: 10, Fees: sdk.NewCoins(sdk.NewInt64Coin("aarch", 7)), MsgTypes: []string{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseFeeTx(10, tc.events, "archway", index))
+		})
+	}
+}
+
+func TestFeeLedger(t *testing.T) {
+	db := testStore(t)
+
+	ledger, err := NewFeeLedger(db)
+	assert.NoError(t, err)
+
+	_, _, ok := ledger.Cursor("archway1abc", "archway-1")
+	assert.False(t, ok)
+
+	ledger.Apply("archway1abc", "archway-1", 100, 0, []FeeTx{
+		{Height: 99, Fees: sdk.NewCoins(sdk.NewInt64Coin("aarch", 10)), Path: "archway-osmosis",
+			MsgTypes: []string{"MsgUpdateClient", "MsgRecvPacket"}},
+		{Height: 100, Fees: sdk.NewCoins(sdk.NewInt64Coin("aarch", 5)), Path: "archway-osmosis",
+			MsgTypes: []string{"MsgRecvPacket"}},
+		{Height: 100, Fees: sdk.NewCoins(sdk.NewInt64Coin("aarch", 1)), MsgTypes: []string{}},
+	})
+	assert.NoError(t, ledger.Save())
+
+	loaded, err := NewFeeLedger(db)
+	assert.NoError(t, err)
+
+	height, skip, ok := loaded.Cursor("archway1abc", "archway-1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), height)
+	assert.Equal(t, 0, skip)
+
+	assert.Equal(t, []FeeTotal{
+		{Account: "archway1abc", ChainID: "archway-1", Denom: "aarch", Path: "", Amount: math.NewInt(1)},
+		{Account: "archway1abc", ChainID: "archway-1", Denom: "aarch", Path: "archway-osmosis", Amount: math.NewInt(15)},
+	}, loaded.Fees())
+	assert.Equal(t, []MsgTotal{
+		{Account: "archway1abc", ChainID: "archway-1", Path: "archway-osmosis", MsgType: "MsgRecvPacket", Count: 2},
+		{Account: "archway1abc", ChainID: "archway-1", Path: "archway-osmosis", MsgType: "MsgUpdateClient", Count: 1},
+	}, loaded.Msgs())
+}
+
+func TestFeeScanWithinHeight(t *testing.T) {
+	// More transactions at height 10 than fit in MaxPages pages.
+	txs := []*coretypes.ResultTx{}
+	fee := abci.Event{Type: feeEventType, Attributes: []abci.EventAttribute{{Key: feeAttribute, Value: "1aarch"}}}
+
+	for i := 0; i < 280; i++ {
+		height := int64(10)
+		if i >= 250 {
+			height = 11
+		}
+
+		txs = append(txs, &coretypes.ResultTx{Height: height, TxResult: abci.ResponseDeliverTx{Events: []abci.Event{fee}}})
+	}
+
+	search := func(_ context.Context, query string, page int) (*coretypes.ResultTxSearch, error) {
+		_, after, _ := strings.Cut(query, "tx.height>")
+		height, err := strconv.ParseInt(after, 10, 64)
+		assert.NoError(t, err)
+
+		matching := []*coretypes.ResultTx{}
+		for _, tx := range txs {
+			if tx.Height > height {
+				matching = append(matching, tx)
+			}
+		}
+
+		start := min((page-1)*feesPerPage, len(matching))
+		end := min(page*feesPerPage, len(matching))
+
+		return &coretypes.ResultTxSearch{Txs: matching[start:end], TotalCount: len(matching)}, nil
+	}
+
+	ledger, err := NewFeeLedger(nil)
+	assert.NoError(t, err)
+	ledger.Apply("archway1abc", "archway-1", 9, 0, nil)
+
+	fc := FeeCollector{MaxPages: 1, Ledger: ledger}
+	account := config.Account{Address: "archway1abc", ChainName: "archway"}
+
+	for _, expected := range []struct {
+		height int64
+		skip   int
+		fees   int64
+	}{
+		{9, 100, 100},
+		{9, 200, 200},
+		{11, 0, 280},
+		{11, 0, 280},
+	} {
+		assert.NoError(t, fc.scanTxs(context.Background(), account, "archway-1", search, pathIndex{}))
+
+		height, skip, _ := ledger.Cursor("archway1abc", "archway-1")
+		assert.Equal(t, expected.height, height)
+		assert.Equal(t, expected.skip, skip)
+		assert.Equal(t, math.NewInt(expected.fees), ledger.Fees()[0].Amount)
+	}
+}
+
+func TestPathHistory(t *testing.T) {
+	db := testStore(t)
+	now := time.Unix(1700000000, 0)
+
+	h, err := NewPathHistory(db)
+	assert.NoError(t, err)
+
+	h.now = func() time.Time { return now }
+
+	since, err := h.UpdateStuckPackets("archway/channel-1", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, since)
+
+	since, err = h.UpdateStuckPackets("archway/channel-1", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, now, *since)
+
+	height, err := h.UpdateClientHeight("archway/07-tendermint-1", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, ClientHeight{Height: 100, ChangedAt: now}, height)
+
+	// Restart keeps stuck packets start and time of the last height change.
+	later := now.Add(time.Hour)
+
+	loaded, err := NewPathHistory(db)
+	assert.NoError(t, err)
+
+	loaded.now = func() time.Time { return later }
+
+	since, err = loaded.UpdateStuckPackets("archway/channel-1", 5)
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(*since))
+
+	height, err = loaded.UpdateClientHeight("archway/07-tendermint-1", 100)
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(height.ChangedAt))
+
+	height, err = loaded.UpdateClientHeight("archway/07-tendermint-1", 120)
+	assert.NoError(t, err)
+	assert.Equal(t, ClientHeight{Height: 120, ChangedAt: later}, height)
+
+	since, err = loaded.UpdateStuckPackets("archway/channel-1", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, since)
+	assert.Nil(t, loaded.StuckSince("archway/channel-1"))
+
+	reloaded, err := NewPathHistory(db)
+	assert.NoError(t, err)
+	assert.Nil(t, reloaded.StuckSince("archway/channel-1"))
+}
+
+func TestPathHistoryNextRecv(t *testing.T) {
+	db := testStore(t)
+	now := time.Unix(1700000000, 0)
+
+	h, err := NewPathHistory(db)
+	assert.NoError(t, err)
+
+	h.now = func() time.Time { return now }
+
+	blocked, err := h.UpdateNextRecv("osmosis/channel-2", 5, true)
+	assert.NoError(t, err)
+	assert.Zero(t, blocked)
+
+	// Restart keeps time since when the sequence hasn't advanced.
+	loaded, err := NewPathHistory(db)
+	assert.NoError(t, err)
+
+	loaded.now = func() time.Time { return now.Add(time.Hour) }
+
+	blocked, err = loaded.UpdateNextRecv("osmosis/channel-2", 5, true)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, blocked)
+
+	blocked, err = loaded.UpdateNextRecv("osmosis/channel-2", 6, true)
+	assert.NoError(t, err)
+	assert.Zero(t, blocked)
+
+	blocked, err = loaded.UpdateNextRecv("osmosis/channel-2", 6, false)
+	assert.NoError(t, err)
+	assert.Zero(t, blocked)
+
+	reloaded, err := NewPathHistory(db)
+	assert.NoError(t, err)
+	assert.Empty(t, reloaded.nextRecv)
+}
+
+func TestCollectPathNextSequences(t *testing.T) {
+	history, err := NewPathHistory(nil)
+	assert.NoError(t, err)
+
+	cc := IBCCollector{Cache: NewSampleCache(), History: history}
+	status := PathStatus{
+		Chain1: ChainStatus{ChainName: "archway", ChainID: "archway-1"},
+		Chain2: ChainStatus{ChainName: "osmosis", ChainID: "osmosis-1"},
+		Channels: []ChannelStatus{{
+			SrcChannelID:     "channel-1",
+			DstChannelID:     "channel-2",
+			SrcNextSequences: &ibc.Sequences{Send: 10, Recv: 5, Ack: 9},
+		}},
+	}
+
+	ch := make(chan prometheus.Metric, 100)
+	cc.collectPath(ch, status)
+	close(ch)
+
+	res := map[*prometheus.Desc][]float64{}
+
+	for m := range ch {
+		metric := &dto.Metric{}
+		assert.NoError(t, m.Write(metric))
+
+		res[m.Desc()] = append(res[m.Desc()], metric.GetGauge().GetValue())
+	}
+
+	// Counters of destination end are unknown and not exported.
+	assert.Equal(t, []float64{10}, res[channelNextSequenceSend])
+	assert.Equal(t, []float64{5}, res[channelNextSequenceRecv])
+	assert.Equal(t, []float64{9}, res[channelNextSequenceAck])
+}
+
+func TestCollectPathOrderedChannelBlocked(t *testing.T) {
+	history, err := NewPathHistory(nil)
+	assert.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	history.now = func() time.Time { return now }
+
+	cc := IBCCollector{Cache: NewSampleCache(), History: history}
+	status := PathStatus{
+		Chain1: ChainStatus{ChainName: "archway", ChainID: "archway-1"},
+		Chain2: ChainStatus{ChainName: "osmosis", ChainID: "osmosis-1"},
+		Channels: []ChannelStatus{{
+			SrcChannelID:     "channel-1",
+			DstChannelID:     "channel-2",
+			Ordering:         "ordered",
+			SrcStuckPackets:  2,
+			SrcNextSequences: &ibc.Sequences{Send: 10, Recv: 1, Ack: 8},
+			DstNextSequences: &ibc.Sequences{Send: 1, Recv: 8, Ack: 1},
+		}},
+	}
+
+	collect := func() []float64 {
+		ch := make(chan prometheus.Metric, 100)
+		cc.collectPath(ch, status)
+		close(ch)
+
+		res := []float64{}
+
+		for m := range ch {
+			if m.Desc() != orderedChannelBlocked {
+				continue
+			}
+
+			metric := &dto.Metric{}
+			assert.NoError(t, m.Write(metric))
+
+			res = append(res, metric.GetGauge().GetValue())
+		}
+
+		return res
+	}
+
+	assert.Equal(t, []float64{0, 0}, collect())
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []float64{60, 0}, collect())
+
+	status.Channels[0].Ordering = "unordered"
+	assert.Empty(t, collect())
+}
+
+func TestWalletBalanceCollectorUnresolvedDenom(t *testing.T) {
+	rpcs := map[string]config.RPC{"archway": {ChainName: "archway", ChainID: "archway-1"}}
+
+	denoms := NewDenomCache()
+	denoms.query = func(_ context.Context, _ config.RPC, _ string) (DenomTrace, error) {
+		return DenomTrace{}, errors.New("connection refused")
+	}
+
+	wb := WalletBalanceCollector{
+		RPCs:     &rpcs,
+		Accounts: []*config.Account{{Address: "archway1a", ChainName: "archway", Denom: "ibc/ABC"}},
+		Denoms:   denoms,
+		query: func(_ context.Context, a *config.Account, _ *map[string]config.RPC) error {
+			a.Balance = math.NewInt(42)
+
+			return nil
+		},
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	wb.Collect(ch)
+	close(ch)
+
+	// Balance is exported with empty trace while the denom can't be resolved.
+	found := false
+
+	for m := range ch {
+		if m.Desc() != walletBalance {
+			continue
+		}
+
+		metric := &dto.Metric{}
+		assert.NoError(t, m.Write(metric))
+		assert.Equal(t, 42.0, metric.GetGauge().GetValue())
+		assert.Contains(t, metric.GetLabel(), &dto.LabelPair{Name: stringPtr("denom"), Value: stringPtr("ibc/ABC")})
+		assert.Contains(t, metric.GetLabel(), &dto.LabelPair{Name: stringPtr("base_denom"), Value: stringPtr("")})
+
+		found = true
+	}
+
+	assert.True(t, found)
+}
+
+func TestEscrowCollector(t *testing.T) {
+	rpcs := map[string]config.RPC{
+		"archway": {ChainName: "archway", ChainID: "archway-1"},
+		"osmosis": {ChainName: "osmosis", ChainID: "osmosis-1"},
+	}
+
+	denoms := NewDenomCache()
+	denoms.query = func(_ context.Context, _ config.RPC, _ string) (DenomTrace, error) {
+		return DenomTrace{BaseDenom: "uusdc", TracePath: "transfer/channel-43"}, nil
+	}
+
+	ec := EscrowCollector{
+		RPCs:   &rpcs,
+		Paths:  []*config.IBCData{{}},
+		Denoms: denoms,
+		query: func(
+			ctx context.Context, _ *config.IBCData, rpcs *map[string]config.RPC, lookup ibc.DenomLookup,
+		) ([]ibc.Escrow, error) {
+			// Traces are resolved through the shared cache.
+			base, tracePath, err := lookup(ctx, (*rpcs)["archway"], "ibc/DEF")
+			assert.NoError(t, err)
+			assert.Equal(t, "uusdc", base)
+			assert.Equal(t, "transfer/channel-43", tracePath)
+
+			return []ibc.Escrow{{
+				ChainName:             "archway",
+				ChannelID:             "channel-1",
+				CounterpartyChainName: "osmosis",
+				CounterpartyChannelID: "channel-2",
+				Denom:                 "aarch",
+				Amount:                math.NewInt(100),
+				CounterpartyDenom:     "ibc/ABC",
+				CounterpartySupply:    math.NewInt(130),
+			}}, errors.New("failed to query supply")
+		},
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	ec.Collect(ch)
+	close(ch)
+
+	res := map[*prometheus.Desc]float64{}
+
+	for m := range ch {
+		metric := &dto.Metric{}
+		assert.NoError(t, m.Write(metric))
+		assert.Contains(t, metric.GetLabel(), &dto.LabelPair{Name: stringPtr("src_chain_id"), Value: stringPtr("archway-1")})
+
+		res[m.Desc()] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[*prometheus.Desc]float64{escrowBalance: 100, escrowMismatch: 30}, res)
+}
+
+func TestIncentiveCollector(t *testing.T) {
+	rpcs := map[string]config.RPC{
+		"archway": {ChainName: "archway", ChainID: "archway-1"},
+		"osmosis": {ChainName: "osmosis", ChainID: "osmosis-1"},
+	}
+
+	// Fees in denoms failed to resolve are exported with empty trace.
+	denoms := NewDenomCache()
+	denoms.query = func(_ context.Context, _ config.RPC, _ string) (DenomTrace, error) {
+		return DenomTrace{}, errors.New("connection refused")
+	}
+
+	ic := IncentiveCollector{
+		RPCs:   &rpcs,
+		Paths:  []*config.IBCData{{}},
+		Denoms: denoms,
+		query: func(_ context.Context, _ *config.IBCData, _ *map[string]config.RPC) (ibc.FeeInfo, error) {
+			return ibc.FeeInfo{
+				Incentives: []ibc.Incentives{{
+					ChainName:             "archway",
+					ChannelID:             "channel-1",
+					CounterpartyChainName: "osmosis",
+					CounterpartyChannelID: "channel-2",
+					Packets:               2,
+					RecvFee:               sdk.NewCoins(sdk.NewInt64Coin("aarch", 20)),
+					AckFee:                sdk.NewCoins(sdk.NewInt64Coin("aarch", 10)),
+					TimeoutFee:            sdk.NewCoins(sdk.NewInt64Coin("ibc/ABC", 5)),
+				}},
+				Payees: []ibc.PayeeRegistration{{
+					ChainName: "archway",
+					ChannelID: "channel-1",
+					Operator:  "relayer",
+					Address:   "archway1a",
+					Payee:     "archway1b",
+				}},
+			}, nil
+		},
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	ic.Collect(ch)
+	close(ch)
+
+	res := map[string]float64{}
+
+	for m := range ch {
+		metric := &dto.Metric{}
+		assert.NoError(t, m.Write(metric))
+
+		key := ""
+
+		for _, l := range metric.GetLabel() {
+			if l.GetName() == "fee_type" || l.GetName() == "payee_type" {
+				key = l.GetValue()
+			}
+		}
+
+		res[key] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"":                   2,
+		"recv":               20,
+		"ack":                10,
+		"timeout":            5,
+		"payee":              1,
+		"counterparty_payee": 0,
+	}, res)
+}
+
+func TestICACollector(t *testing.T) {
+	rpcs := map[string]config.RPC{
+		"archway": {ChainName: "archway", ChainID: "archway-1"},
+		"osmosis": {ChainName: "osmosis", ChainID: "osmosis-1"},
+	}
+
+	channel := func(owner, channelID, state string, pending int) ibc.ICAChannel {
+		return ibc.ICAChannel{
+			ControllerChainName: "archway",
+			HostChainName:       "osmosis",
+			ConnectionID:        "connection-1",
+			Owner:               owner,
+			ChannelID:           channelID,
+			State:               state,
+			PendingPackets:      pending,
+		}
+	}
+
+	ic := ICACollector{
+		RPCs:  &rpcs,
+		Paths: []*config.IBCData{{}},
+		query: func(_ context.Context, _ *config.IBCData, _ *map[string]config.RPC) ([]ibc.ICAChannel, error) {
+			return []ibc.ICAChannel{
+				channel("archway1a", "channel-1", "closed", 0),
+				channel("archway1a", "channel-5", "open", 2),
+				channel("archway1b", "channel-2", "closed", 0),
+			}, nil
+		},
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	ic.Collect(ch)
+	close(ch)
+
+	res := map[string]float64{}
+
+	for m := range ch {
+		metric := &dto.Metric{}
+		assert.NoError(t, m.Write(metric))
+
+		labels := map[string]string{}
+		for _, l := range metric.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		switch m.Desc() {
+		case icaChannelState:
+			res["state/"+labels["controller_channel_id"]+"/"+labels["state"]] = metric.GetGauge().GetValue()
+		case icaPendingPackets:
+			res["pending/"+labels["controller_channel_id"]] = metric.GetGauge().GetValue()
+		case icaClosed:
+			res["closed/"+labels["owner"]] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"state/channel-1/closed": 1,
+		"state/channel-5/open":   1,
+		"state/channel-2/closed": 1,
+		"pending/channel-5":      2,
+		"closed/archway1a":       0,
+		"closed/archway1b":       1,
+	}, res)
+}
+
+func TestGetRouteStatus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	expiry := now.Add(48 * time.Hour)
+	expiring := now.Add(time.Hour)
+
+	paths := []*config.IBCData{
+		{Chain1: config.IBCChainMeta{ChainName: "archway"}, Chain2: config.IBCChainMeta{ChainName: "osmosis"}},
+		{Chain1: config.IBCChainMeta{ChainName: "noble"}, Chain2: config.IBCChainMeta{ChainName: "osmosis"}},
+	}
+
+	store := NewStatusStore()
+	store.UpdatePath(PathStatus{
+		Name:   "archway-osmosis",
+		Chain1: ChainStatus{ChainName: "archway", ClientExpiry: &expiry},
+		Chain2: ChainStatus{ChainName: "osmosis", ClientExpiry: &expiry},
+		Channels: []ChannelStatus{
+			{SrcChannelID: "channel-1", SrcPortID: "transfer", DstPortID: "transfer", DstStuckPackets: 4},
+		},
+	})
+	// Path is stored in registry order, reversed to the route.
+	store.UpdatePath(PathStatus{
+		Name:   "noble-osmosis",
+		Chain1: ChainStatus{ChainName: "noble", ClientExpiry: &expiry},
+		Chain2: ChainStatus{ChainName: "osmosis", ClientExpiry: &expiring, ClientExpiryThreshold: 2 * time.Hour},
+		Channels: []ChannelStatus{
+			{DstChannelID: "channel-750", SrcPortID: "transfer", DstPortID: "transfer", DstStuckPackets: 3},
+			{DstChannelID: "channel-9", SrcPortID: "wasm.osmo1a", DstPortID: "transfer", DstStuckPackets: 5},
+		},
+	})
+
+	history, err := NewPathHistory(nil)
+	assert.NoError(t, err)
+
+	history.now = func() time.Time { return now.Add(-time.Hour) }
+	_, err = history.UpdateStuckPackets(ChannelKey("osmosis", "channel-750"), 3)
+	assert.NoError(t, err)
+
+	route := &config.ForwardRoute{Name: "archway-noble", Chains: []string{"archway", "osmosis", "noble", "axelar"}}
+	status := GetRouteStatus(route, paths, store, history, now)
+
+	stuckSince := now.Add(-time.Hour)
+
+	assert.Equal(t, []HopStatus{
+		{
+			Path:         "archway-osmosis",
+			SrcChainName: "archway",
+			DstChainName: "osmosis",
+			ClientExpiry: &expiry,
+		},
+		{
+			Path:         "noble-osmosis",
+			SrcChainName: "osmosis",
+			DstChainName: "noble",
+			ClientExpiry: &expiring,
+			StuckPackets: 3,
+			StuckSince:   &stuckSince,
+			Degraded:     true,
+			Reasons:      []string{"client expires within threshold", "3 stuck packets"},
+		},
+		{
+			Path:         "noble-axelar",
+			SrcChainName: "noble",
+			DstChainName: "axelar",
+			Degraded:     true,
+			Reasons:      []string{"path not found in registry"},
+		},
+	}, status.Hops)
+	assert.Equal(t, &expiring, status.ClientExpiry)
+	assert.Equal(t, 3, status.StuckPackets)
+	assert.Equal(t, "noble-osmosis", status.SlowestHop)
+	assert.True(t, status.Degraded)
+}
+
+func TestPacketCache(t *testing.T) {
+	queried := [][]uint64{}
+
+	cache := NewPacketCache()
+	cache.query = func(_ context.Context, _ config.RPC, _, _ string, sequences []uint64) []ibc.Packet {
+		queried = append(queried, sequences)
+
+		packets := []ibc.Packet{}
+		for _, seq := range sequences {
+			if seq == 3 {
+				packets = append(packets, ibc.Packet{Sequence: seq, Error: "tx not found"})
+			} else {
+				packets = append(packets, ibc.Packet{Sequence: seq, Denom: "aarch"})
+			}
+		}
+
+		return packets
+	}
+
+	rpcs := &map[string]config.RPC{
+		"archway": {ChainName: "archway", ChainID: "archway-1"},
+		"osmosis": {ChainName: "osmosis", ChainID: "osmosis-1"},
+	}
+	status := func(src, dst []uint64) PathStatus {
+		return PathStatus{
+			Name:   "archway-osmosis",
+			Chain1: ChainStatus{ChainName: "archway", ChainID: "archway-1"},
+			Chain2: ChainStatus{ChainName: "osmosis", ChainID: "osmosis-1"},
+			Channels: []ChannelStatus{
+				{SrcChannelID: "channel-1", DstChannelID: "channel-2", SrcSequences: src, DstSequences: dst},
+			},
+		}
+	}
+
+	res := cache.StuckPackets(context.Background(), rpcs, status([]uint64{1, 2, 3}, nil), "")
+	assert.Len(t, res, 1)
+	assert.Equal(t, "archway", res[0].ChainName)
+	assert.Equal(t, "channel-2", res[0].CounterpartyChannelID)
+	assert.Equal(t, []ibc.Packet{
+		{Sequence: 1, Denom: "aarch"},
+		{Sequence: 2, Denom: "aarch"},
+		{Sequence: 3, Error: "tx not found"},
+	}, res[0].Packets)
+
+	// Cached packets aren't queried again, failed ones are backed off.
+	res = cache.StuckPackets(context.Background(), rpcs, status([]uint64{2, 3, 4}, []uint64{7}), "channel-2")
+	assert.Len(t, res, 2)
+	assert.Equal(t, "osmosis", res[1].ChainName)
+	assert.Equal(t, ibc.Packet{Sequence: 3, Error: "tx not found"}, res[0].Packets[1])
+	assert.Equal(t, [][]uint64{{1, 2, 3}, {4}, {7}}, queried)
+
+	// Failed packets are retried after backoff.
+	cache.now = func() time.Time { return time.Now().Add(packetRetryBackoff) }
+	cache.StuckPackets(context.Background(), rpcs, status([]uint64{2, 3, 4}, nil), "")
+	assert.Equal(t, []uint64{3}, queried[3])
+
+	// Relayed packets are dropped from cache.
+	res = cache.StuckPackets(context.Background(), rpcs, status([]uint64{1}, nil), "")
+	assert.Equal(t, []ibc.Packet{{Sequence: 1, Denom: "aarch"}}, res[0].Packets)
+	assert.Equal(t, []uint64{1}, queried[4])
+
+	assert.Empty(t, cache.StuckPackets(context.Background(), rpcs, status([]uint64{1}, nil), "channel-9"))
+}
+
+func TestPacketCacheLookupLimit(t *testing.T) {
+	queried := [][]uint64{}
+
+	cache := NewPacketCache()
+	cache.query = func(_ context.Context, _ config.RPC, _, _ string, sequences []uint64) []ibc.Packet {
+		queried = append(queried, sequences)
+
+		packets := []ibc.Packet{}
+		for _, seq := range sequences {
+			packets = append(packets, ibc.Packet{Sequence: seq})
+		}
+
+		return packets
+	}
+
+	sequences := []uint64{}
+	for seq := uint64(1); seq <= ibc.MaxPacketLookups+5; seq++ {
+		sequences = append(sequences, seq)
+	}
+
+	rpc := config.RPC{ChainName: "archway", ChainID: "archway-1"}
+
+	packets := cache.Lookup(context.Background(), rpc, "channel-1", "transfer", sequences)
+	assert.Len(t, packets, ibc.MaxPacketLookups+5)
+	assert.Equal(t, sequences[:ibc.MaxPacketLookups], queried[0])
+
+	// Packets over limit are unknown until queried by the next lookup.
+	for _, p := range packets[ibc.MaxPacketLookups:] {
+		assert.Equal(t, ibc.ErrPacketLookupSkipped, p.Error)
+	}
+
+	packets = cache.Lookup(context.Background(), rpc, "channel-1", "transfer", sequences)
+	assert.Equal(t, sequences[ibc.MaxPacketLookups:], queried[1])
+
+	for _, p := range packets {
+		assert.Empty(t, p.Error)
+	}
+}
+
+func TestDenomCache(t *testing.T) {
+	queried := []string{}
+	fail := true
+
+	cache := NewDenomCache()
+	cache.query = func(_ context.Context, rpc config.RPC, hash string) (DenomTrace, error) {
+		queried = append(queried, rpc.ChainID+"/"+hash)
+
+		if fail {
+			return DenomTrace{}, errors.New("connection refused")
+		}
+
+		return DenomTrace{BaseDenom: "uusdc", TracePath: "transfer/channel-43"}, nil
+	}
+
+	ctx := context.Background()
+	rpc := config.RPC{ChainName: "archway", ChainID: "archway-1"}
+
+	trace, err := cache.Trace(ctx, rpc, "aarch")
+	assert.NoError(t, err)
+	assert.Equal(t, DenomTrace{BaseDenom: "aarch"}, trace)
+
+	_, err = cache.Trace(ctx, rpc, "ibc/ABC")
+	assert.EqualError(t, err, "connection refused")
+
+	// Failed queries are retried and traces are queried once.
+	fail = false
+
+	for i := 0; i < 2; i++ {
+		trace, err := cache.Trace(ctx, rpc, "ibc/ABC")
+		assert.NoError(t, err)
+		assert.Equal(t, DenomTrace{BaseDenom: "uusdc", TracePath: "transfer/channel-43"}, trace)
+	}
+
+	assert.Equal(t, []string{"archway-1/ABC", "archway-1/ABC"}, queried)
+
+	base, tracePath, err := cache.Lookup(ctx, rpc, "ibc/ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, "uusdc", base)
+	assert.Equal(t, "transfer/channel-43", tracePath)
+
+	trace, err = (*DenomCache)(nil).Trace(ctx, rpc, "aarch")
+	assert.NoError(t, err)
+	assert.Equal(t, DenomTrace{BaseDenom: "aarch"}, trace)
+}
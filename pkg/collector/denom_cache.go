@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// DenomTrace is the origin of a denom. Native denoms are their own base
+// denom with empty trace path.
+type DenomTrace struct {
+	BaseDenom string `json:"base_denom"`
+	// TracePath is the path of ports and channels the token was
+	// transferred through, e.g. transfer/channel-43.
+	TracePath string `json:"trace_path"`
+}
+
+// denomTraceQuery returns trace of IBC denom with hash on chain with rpc.
+type denomTraceQuery func(ctx context.Context, rpc config.RPC, hash string) (DenomTrace, error)
+
+// DenomCache keeps traces of IBC denoms queried from the transfer module.
+// Trace of a denom hash never changes, so it is queried once per chain and
+// kept once resolved. Failed queries are retried on the next collection.
+type DenomCache struct {
+	mu     sync.Mutex
+	traces map[string]DenomTrace
+	query  denomTraceQuery
+}
+
+func NewDenomCache() *DenomCache {
+	return &DenomCache{
+		traces: map[string]DenomTrace{},
+		query:  queryDenomTrace,
+	}
+}
+
+func queryDenomTrace(ctx context.Context, rpc config.RPC, hash string) (DenomTrace, error) {
+	c, err := chain.PrepChain(ctx, chain.Info{
+		ChainID: rpc.ChainID,
+		RPCAddr: rpc.URL,
+		Timeout: rpc.Timeout,
+	})
+	if err != nil {
+		return DenomTrace{}, fmt.Errorf("error: %w for %s", err, rpc.ChainID)
+	}
+
+	trace, err := c.ChainProvider.QueryDenomTrace(ctx, hash)
+	if err != nil {
+		return DenomTrace{}, fmt.Errorf("error: %w querying trace of %s on %s", err, hash, rpc.ChainID)
+	}
+
+	return DenomTrace{BaseDenom: trace.BaseDenom, TracePath: trace.Path}, nil
+}
+
+// Trace returns trace of denom on chain with rpc. IBC denoms which were never
+// resolved return empty trace with the query error, callers still export
+// their samples with empty trace labels until the trace resolves. A nil
+// cache returns empty traces of IBC denoms without querying them.
+func (c *DenomCache) Trace(ctx context.Context, rpc config.RPC, denom string) (DenomTrace, error) {
+	hash, ok := strings.CutPrefix(denom, transfertypes.DenomPrefix+"/")
+	if !ok {
+		return DenomTrace{BaseDenom: denom}, nil
+	}
+
+	if c == nil {
+		return DenomTrace{}, nil
+	}
+
+	key := rpc.ChainID + "/" + denom
+
+	c.mu.Lock()
+	trace, ok := c.traces[key]
+	c.mu.Unlock()
+
+	if ok {
+		return trace, nil
+	}
+
+	trace, err := c.query(ctx, rpc, hash)
+	if err != nil {
+		return DenomTrace{}, err
+	}
+
+	c.mu.Lock()
+	c.traces[key] = trace
+	c.mu.Unlock()
+
+	return trace, nil
+}
+
+// Lookup returns base denom and trace path of IBC denom on chain with rpc,
+// with error if it can't be resolved.
+func (c *DenomCache) Lookup(ctx context.Context, rpc config.RPC, denom string) (string, string, error) {
+	trace, err := c.Trace(ctx, rpc, denom)
+
+	return trace.BaseDenom, trace.TracePath, err
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"cosmossdk.io/math"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	escrowBalanceMetricName  = "cosmos_ibc_escrow_balance"
+	escrowMismatchMetricName = "cosmos_ibc_escrow_mismatch"
+)
+
+var (
+	escrowBalance = prometheus.NewDesc(
+		escrowBalanceMetricName,
+		"Returns balance of ICS-20 escrow account of a channel.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"address",
+			"denom",
+			"base_denom",
+			"trace_path",
+			"dst_denom",
+		},
+		nil,
+	)
+	escrowMismatch = prometheus.NewDesc(
+		escrowMismatchMetricName,
+		"Returns supply of vouchers on the counterparty minus escrowed amount, positive when vouchers aren't backed.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"address",
+			"denom",
+			"base_denom",
+			"trace_path",
+			"dst_denom",
+		},
+		nil,
+	)
+)
+
+type escrowQuery func(
+	ctx context.Context, path *config.IBCData, rpcs *map[string]config.RPC, lookup ibc.DenomLookup,
+) ([]ibc.Escrow, error)
+
+// EscrowCollector exports balances of ICS-20 escrow accounts of transfer
+// channels and their mismatch with supply of vouchers on counterparties.
+type EscrowCollector struct {
+	RPCs   *map[string]config.RPC
+	Paths  []*config.IBCData
+	Denoms *DenomCache
+
+	query escrowQuery
+}
+
+func (ec EscrowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- escrowBalance
+	ch <- escrowMismatch
+}
+
+func (ec EscrowCollector) Collect(ch chan<- prometheus.Metric) {
+	log.Debug("Start collecting", zap.String("metric", escrowBalanceMetricName))
+
+	query := ec.query
+	if query == nil {
+		query = ibc.GetEscrows
+	}
+
+	var lookup ibc.DenomLookup
+	if ec.Denoms != nil {
+		lookup = ec.Denoms.Lookup
+	}
+
+	var wg sync.WaitGroup
+
+	for _, p := range ec.Paths {
+		wg.Add(1)
+
+		go func(path *config.IBCData) {
+			defer wg.Done()
+
+			escrows, err := query(ctx, path, ec.RPCs, lookup)
+			if err != nil {
+				log.Error(err.Error(), zap.String("path", path.Name()))
+			}
+
+			for _, e := range escrows {
+				labels := []string{
+					e.ChannelID,
+					e.CounterpartyChannelID,
+					(*ec.RPCs)[e.ChainName].ChainID,
+					(*ec.RPCs)[e.CounterpartyChainName].ChainID,
+					e.ChainName,
+					e.CounterpartyChainName,
+					e.Address,
+					e.Denom,
+					e.BaseDenom,
+					e.TracePath,
+					e.CounterpartyDenom,
+				}
+
+				ch <- prometheus.MustNewConstMetric(escrowBalance, prometheus.GaugeValue, intToFloat(e.Amount), labels...)
+				ch <- prometheus.MustNewConstMetric(
+					escrowMismatch, prometheus.GaugeValue, intToFloat(e.CounterpartySupply.Sub(e.Amount)), labels...,
+				)
+			}
+		}(p)
+	}
+
+	wg.Wait()
+
+	log.Debug("Stop collecting", zap.String("metric", escrowBalanceMetricName))
+}
+
+func intToFloat(i math.Int) float64 {
+	f, _ := big.NewFloat(0.0).SetInt(i.BigInt()).Float64()
+
+	return f
+}
@@ -0,0 +1,298 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	feesSpentMetricName = "cosmos_relayer_fees_spent_total"
+	ibcMsgsMetricName   = "cosmos_relayer_ibc_msgs_total"
+
+	feesPerPage  = 100
+	feeEventType = "tx"
+	feeAttribute = "fee"
+)
+
+var (
+	feesSpent = prometheus.NewDesc(
+		feesSpentMetricName,
+		"Returns total fees spent by transactions of an account.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "path"}, nil,
+	)
+	ibcMsgs = prometheus.NewDesc(
+		ibcMsgsMetricName,
+		"Returns total IBC messages in transactions of an account.",
+		[]string{"account", "chain_id", "path", "msg_type"}, nil,
+	)
+
+	// ibcMsgTypes maps IBC event types to messages emitting them.
+	ibcMsgTypes = map[string]string{
+		clienttypes.EventTypeUpdateClient:       "MsgUpdateClient",
+		chantypes.EventTypeRecvPacket:           "MsgRecvPacket",
+		chantypes.EventTypeAcknowledgePacket:    "MsgAcknowledgement",
+		chantypes.EventTypeTimeoutPacket:        "MsgTimeout",
+		chantypes.EventTypeTimeoutPacketOnClose: "MsgTimeoutOnClose",
+	}
+)
+
+// FeeCollector searches new transactions sent by configured accounts on
+// every collection and exports fees they spent per IBC path.
+type FeeCollector struct {
+	RPCs     *map[string]config.RPC
+	Paths    []*config.IBCData
+	Accounts []*config.Account
+	MaxPages int
+	Ledger   *FeeLedger
+	Denoms   *DenomCache
+}
+
+func (fc FeeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- feesSpent
+	ch <- ibcMsgs
+}
+
+func (fc FeeCollector) Collect(ch chan<- prometheus.Metric) {
+	log.Debug("Start collecting", zap.String("metric", feesSpentMetricName))
+
+	// Concurrent scrapes export totals without searching the same
+	// transactions again.
+	if fc.Ledger.scan.TryLock() {
+		fc.scan()
+		fc.Ledger.scan.Unlock()
+	}
+
+	// Fees are recorded by chain ID.
+	rpcs := map[string]config.RPC{}
+	for _, rpc := range *fc.RPCs {
+		rpcs[rpc.ChainID] = rpc
+	}
+
+	for _, f := range fc.Ledger.Fees() {
+		amount, _ := big.NewFloat(0.0).SetInt(f.Amount.BigInt()).Float64()
+		trace, err := fc.Denoms.Trace(ctx, rpcs[f.ChainID], f.Denom)
+		if err != nil {
+			log.Error(err.Error(), zap.String("denom", f.Denom))
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			feesSpent, prometheus.CounterValue, amount,
+			f.Account, f.ChainID, f.Denom, trace.BaseDenom, trace.TracePath, f.Path,
+		)
+	}
+
+	for _, m := range fc.Ledger.Msgs() {
+		ch <- prometheus.MustNewConstMetric(
+			ibcMsgs, prometheus.CounterValue, float64(m.Count), m.Account, m.ChainID, m.Path, m.MsgType,
+		)
+	}
+
+	log.Debug("Stop collecting", zap.String("metric", feesSpentMetricName))
+}
+
+func (fc FeeCollector) scan() {
+	var wg sync.WaitGroup
+
+	index := newPathIndex(fc.Paths)
+	seen := map[string]bool{}
+
+	for _, a := range fc.Accounts {
+		// Accounts are listed once per denom.
+		if seen[accountKey(a.Address, a.ChainName)] {
+			continue
+		}
+
+		seen[accountKey(a.Address, a.ChainName)] = true
+
+		wg.Add(1)
+
+		go func(account config.Account) {
+			defer wg.Done()
+
+			if err := fc.scanAccount(ctx, account, index); err != nil {
+				log.Error(err.Error(), zap.String("account", account.Address))
+			}
+		}(*a)
+	}
+
+	wg.Wait()
+
+	if err := fc.Ledger.Save(); err != nil {
+		log.Error("Failed to save fee ledger", zap.Error(err))
+	}
+}
+
+// scanAccount processes transactions of account since the last processed
+// height. Accounts seen for the first time start at the latest height.
+func (fc FeeCollector) scanAccount(ctx context.Context, account config.Account, index pathIndex) error {
+	rpc := (*fc.RPCs)[account.ChainName]
+
+	c, err := chain.PrepChain(ctx, chain.Info{
+		ChainID: rpc.ChainID,
+		RPCAddr: rpc.URL,
+		Timeout: rpc.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, _, ok := fc.Ledger.Cursor(account.Address, rpc.ChainID); !ok {
+		latest, err := c.ChainProvider.QueryLatestHeight(ctx)
+		if err != nil {
+			return err
+		}
+
+		fc.Ledger.Apply(account.Address, rpc.ChainID, latest, 0, nil)
+
+		return nil
+	}
+
+	search := func(ctx context.Context, query string, page int) (*coretypes.ResultTxSearch, error) {
+		return chain.TxSearch(ctx, c, query, page, feesPerPage, "asc")
+	}
+
+	return fc.scanTxs(ctx, account, rpc.ChainID, search, index)
+}
+
+// txSearch returns page of transactions matching query in ascending order.
+type txSearch func(ctx context.Context, query string, page int) (*coretypes.ResultTxSearch, error)
+
+// scanTxs applies transactions of account after its ledger cursor, at most
+// MaxPages pages of them. Transactions within a height are ordered, so when
+// pages end within a height the cursor keeps how many of its transactions
+// were processed and the next scan continues from the page after them.
+func (fc FeeCollector) scanTxs(
+	ctx context.Context, account config.Account, chainID string, search txSearch, index pathIndex,
+) error {
+	height, skip, _ := fc.Ledger.Cursor(account.Address, chainID)
+
+	query := fmt.Sprintf("message.sender='%s' AND tx.height>%d", account.Address, height)
+	first := skip/feesPerPage + 1
+	txs := []FeeTx{}
+
+	for page := first; page < first+fc.MaxPages; page++ {
+		res, err := search(ctx, query, page)
+		if err != nil {
+			return err
+		}
+
+		for i, tx := range res.Txs {
+			if page == first && i < skip%feesPerPage {
+				continue
+			}
+
+			txs = append(txs, parseFeeTx(tx.Height, tx.TxResult.Events, account.ChainName, index))
+		}
+
+		if page*feesPerPage >= res.TotalCount {
+			if len(txs) > 0 {
+				height, skip = txs[len(txs)-1].Height, 0
+			}
+
+			fc.Ledger.Apply(account.Address, chainID, height, skip, txs)
+
+			return nil
+		}
+	}
+
+	if len(txs) == 0 {
+		return nil
+	}
+
+	// Transactions of the last height may continue on the next page, the
+	// next scan skips those processed.
+	last := txs[len(txs)-1].Height
+	processed := 0
+
+	if last == height+1 {
+		processed = skip
+	}
+
+	for _, tx := range txs {
+		if tx.Height == last {
+			processed++
+		}
+	}
+
+	fc.Ledger.Apply(account.Address, chainID, last-1, processed, txs)
+
+	return nil
+}
+
+// pathIndex maps chain client and channel IDs to IBC path names.
+type pathIndex map[string]string
+
+func newPathIndex(paths []*config.IBCData) pathIndex {
+	index := pathIndex{}
+
+	for _, p := range paths {
+		index[p.Chain1.ChainName+"/"+p.Chain1.ClientID] = p.Name()
+		index[p.Chain2.ChainName+"/"+p.Chain2.ClientID] = p.Name()
+
+		for _, c := range p.Channels {
+			index[p.Chain1.ChainName+"/"+c.Chain1.ChannelID] = p.Name()
+			index[p.Chain2.ChainName+"/"+c.Chain2.ChannelID] = p.Name()
+		}
+	}
+
+	return index
+}
+
+// parseFeeTx returns fees and IBC messages of a transaction on chainName
+// from its events. Path is taken from the first IBC message of a known path.
+func parseFeeTx(height int64, events []abci.Event, chainName string, index pathIndex) FeeTx {
+	tx := FeeTx{Height: height, Fees: sdk.Coins{}, MsgTypes: []string{}}
+
+	for _, e := range events {
+		attrs := map[string]string{}
+		for _, a := range e.Attributes {
+			attrs[a.Key] = a.Value
+		}
+
+		if fee, ok := attrs[feeAttribute]; ok && e.Type == feeEventType {
+			coins, err := sdk.ParseCoinsNormalized(fee)
+			if err != nil {
+				log.Error("Failed to parse transaction fee", zap.String("fee", fee), zap.Error(err))
+			} else {
+				tx.Fees = tx.Fees.Add(coins...)
+			}
+		}
+
+		msgType, ok := ibcMsgTypes[e.Type]
+		if !ok {
+			continue
+		}
+
+		tx.MsgTypes = append(tx.MsgTypes, msgType)
+
+		// Channel of the chain the transaction is on.
+		id := attrs[chantypes.AttributeKeySrcChannel]
+
+		switch e.Type {
+		case clienttypes.EventTypeUpdateClient:
+			id = attrs[clienttypes.AttributeKeyClientID]
+		case chantypes.EventTypeRecvPacket:
+			id = attrs[chantypes.AttributeKeyDstChannel]
+		}
+
+		if path, ok := index[chainName+"/"+id]; ok && tx.Path == "" {
+			tx.Path = path
+		}
+	}
+
+	return tx
+}
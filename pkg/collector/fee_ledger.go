@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/archway-network/relayer_exporter/pkg/store"
+)
+
+const (
+	feeLedgerBucket = "fees"
+	feeLedgerKey    = "ledger"
+)
+
+// FeeTx is a processed transaction of an account.
+type FeeTx struct {
+	Height int64
+	Fees   sdk.Coins
+	// Path is the IBC path relayed by the transaction, empty if unknown.
+	Path     string
+	MsgTypes []string
+}
+
+type FeeTotal struct {
+	Account string   `json:"account"`
+	ChainID string   `json:"chain_id"`
+	Denom   string   `json:"denom"`
+	Path    string   `json:"path"`
+	Amount  math.Int `json:"amount"`
+}
+
+type MsgTotal struct {
+	Account string `json:"account"`
+	ChainID string `json:"chain_id"`
+	Path    string `json:"path"`
+	MsgType string `json:"msg_type"`
+	Count   uint64 `json:"count"`
+}
+
+type feeLedgerState struct {
+	// Heights holds the last processed height of each account.
+	Heights map[string]int64 `json:"heights"`
+	// Skips holds how many transactions of each account at the height
+	// after the last processed one were processed already, when a scan
+	// stopped within a height.
+	Skips map[string]int      `json:"skips,omitempty"`
+	Fees  map[string]FeeTotal `json:"fees"`
+	Msgs  map[string]MsgTotal `json:"msgs"`
+}
+
+// FeeLedger accumulates fees spent by accounts together with the last
+// processed height, so transactions are counted once across scrapes and,
+// when persisted, across restarts.
+type FeeLedger struct {
+	mu    sync.Mutex
+	scan  sync.Mutex
+	store *store.Store
+	state feeLedgerState
+}
+
+// NewFeeLedger returns ledger loaded from s, which may be nil.
+func NewFeeLedger(s *store.Store) (*FeeLedger, error) {
+	l := &FeeLedger{
+		store: s,
+		state: feeLedgerState{
+			Heights: map[string]int64{},
+			Skips:   map[string]int{},
+			Fees:    map[string]FeeTotal{},
+			Msgs:    map[string]MsgTotal{},
+		},
+	}
+
+	if _, err := s.Get(feeLedgerBucket, feeLedgerKey, &l.state); err != nil {
+		return nil, err
+	}
+
+	if l.state.Skips == nil {
+		l.state.Skips = map[string]int{}
+	}
+
+	return l, nil
+}
+
+func accountKey(account, chainID string) string {
+	return account + "/" + chainID
+}
+
+// Cursor returns the last processed height of account on chain and how many
+// transactions at the next height were processed already.
+func (l *FeeLedger) Cursor(account, chainID string) (int64, int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.state.Heights[accountKey(account, chainID)]
+
+	return h, l.state.Skips[accountKey(account, chainID)], ok
+}
+
+// Apply adds fees and messages of txs and sets the cursor to the last
+// processed height and skip transactions processed at the next height.
+func (l *FeeLedger) Apply(account, chainID string, height int64, skip int, txs []FeeTx) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, tx := range txs {
+		for _, coin := range tx.Fees {
+			key := accountKey(account, chainID) + "/" + tx.Path + "/" + coin.Denom
+
+			total, ok := l.state.Fees[key]
+			if !ok {
+				total = FeeTotal{Account: account, ChainID: chainID, Denom: coin.Denom, Path: tx.Path, Amount: math.ZeroInt()}
+			}
+
+			total.Amount = total.Amount.Add(coin.Amount)
+			l.state.Fees[key] = total
+		}
+
+		for _, msgType := range tx.MsgTypes {
+			key := accountKey(account, chainID) + "/" + tx.Path + "/" + msgType
+
+			total, ok := l.state.Msgs[key]
+			if !ok {
+				total = MsgTotal{Account: account, ChainID: chainID, Path: tx.Path, MsgType: msgType}
+			}
+
+			total.Count++
+			l.state.Msgs[key] = total
+		}
+	}
+
+	l.state.Heights[accountKey(account, chainID)] = height
+
+	if skip > 0 {
+		l.state.Skips[accountKey(account, chainID)] = skip
+	} else {
+		delete(l.state.Skips, accountKey(account, chainID))
+	}
+}
+
+// Fees returns fee totals sorted by key.
+func (l *FeeLedger) Fees() []FeeTotal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fees := make([]FeeTotal, 0, len(l.state.Fees))
+	for _, key := range sortedKeys(l.state.Fees) {
+		fees = append(fees, l.state.Fees[key])
+	}
+
+	return fees
+}
+
+// Msgs returns relayed message totals sorted by key.
+func (l *FeeLedger) Msgs() []MsgTotal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	msgs := make([]MsgTotal, 0, len(l.state.Msgs))
+	for _, key := range sortedKeys(l.state.Msgs) {
+		msgs = append(msgs, l.state.Msgs[key])
+	}
+
+	return msgs
+}
+
+// Save writes ledger to store.
+func (l *FeeLedger) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.store.Put(feeLedgerBucket, feeLedgerKey, l.state)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,8 +1,8 @@
 package collector
 
 import (
+	"context"
 	"fmt"
-	"reflect"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,11 +14,30 @@ import (
 )
 
 const (
-	clientExpiryMetricName        = "cosmos_ibc_client_expiry"
-	channelStuckPacketsMetricName = "cosmos_ibc_stuck_packets"
-	configMissingMetricName       = "cosmos_ibc_config_missing"
+	clientExpiryMetricName                   = "cosmos_ibc_client_expiry"
+	clientExpiryLastSuccessMetricName        = "cosmos_ibc_client_expiry_last_success_timestamp"
+	clientExpiryThresholdMetricName          = "cosmos_ibc_client_expiry_threshold_seconds"
+	clientHeightMetricName                   = "cosmos_ibc_client_height"
+	clientHeightChangedMetricName            = "cosmos_ibc_client_height_changed_timestamp"
+	clientLastUpdateMetricName               = "cosmos_ibc_client_last_update_timestamp"
+	clientUpdateIntervalMetricName           = "cosmos_ibc_client_update_interval_seconds"
+	channelStuckPacketsMetricName            = "cosmos_ibc_stuck_packets"
+	channelStuckPacketsSinceMetricName       = "cosmos_ibc_stuck_packets_since_timestamp"
+	channelStuckPacketsLastSuccessMetricName = "cosmos_ibc_stuck_packets_last_success_timestamp"
+	channelNextSequenceSendMetricName        = "cosmos_ibc_channel_next_sequence_send"
+	channelNextSequenceRecvMetricName        = "cosmos_ibc_channel_next_sequence_recv"
+	channelNextSequenceAckMetricName         = "cosmos_ibc_channel_next_sequence_ack"
+	orderedChannelBlockedMetricName          = "cosmos_ibc_ordered_channel_blocked"
+	configMissingMetricName                  = "cosmos_ibc_config_missing"
+
+	packetStateRelayable = "relayable"
+	packetStateTimedOut  = "timed_out"
+	packetStateUnknown   = "unknown"
 )
 
+// packetStates are values of state label of stuck packets.
+var packetStates = []string{packetStateRelayable, packetStateTimedOut, packetStateUnknown}
+
 var (
 	clientExpiry = prometheus.NewDesc(
 		clientExpiryMetricName,
@@ -34,9 +53,87 @@ var (
 		},
 		nil,
 	)
+	clientExpiryLastSuccess = prometheus.NewDesc(
+		clientExpiryLastSuccessMetricName,
+		"Returns unixtime of the last successful light client expiry query.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
+	clientExpiryThreshold = prometheus.NewDesc(
+		clientExpiryThresholdMetricName,
+		"Returns configured light client expiry threshold in seconds.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
+	clientHeight = prometheus.NewDesc(
+		clientHeightMetricName,
+		"Returns latest height of light client.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
+	clientHeightChanged = prometheus.NewDesc(
+		clientHeightChangedMetricName,
+		"Returns unixtime when latest height of light client was first seen.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
+	clientLastUpdate = prometheus.NewDesc(
+		clientLastUpdateMetricName,
+		"Returns unixtime of the last light client update.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
+	clientUpdateInterval = prometheus.NewDesc(
+		clientUpdateIntervalMetricName,
+		"Returns observed interval between the two most recent light client updates in seconds.",
+		[]string{
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"client_id",
+			"discord_ids",
+		},
+		nil,
+	)
 	channelStuckPackets = prometheus.NewDesc(
 		channelStuckPacketsMetricName,
-		"Returns stuck packets for a channel.",
+		"Returns stuck packets for a channel by state, relayable, timed_out or unknown.",
 		[]string{
 			"src_channel_id",
 			"dst_channel_id",
@@ -45,10 +142,96 @@ var (
 			"src_chain_name",
 			"dst_chain_name",
 			"discord_ids",
+			"state",
 			"status",
 		},
 		nil,
 	)
+	channelStuckPacketsLastSuccess = prometheus.NewDesc(
+		channelStuckPacketsLastSuccessMetricName,
+		"Returns unixtime of the last successful stuck packets query for a channel.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	channelStuckPacketsSince = prometheus.NewDesc(
+		channelStuckPacketsSinceMetricName,
+		"Returns unixtime since when a channel has stuck packets.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	channelNextSequenceSend = prometheus.NewDesc(
+		channelNextSequenceSendMetricName,
+		"Returns sequence of the next packet sent on a channel.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	channelNextSequenceRecv = prometheus.NewDesc(
+		channelNextSequenceRecvMetricName,
+		"Returns sequence of the next packet received on a channel.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	channelNextSequenceAck = prometheus.NewDesc(
+		channelNextSequenceAckMetricName,
+		"Returns sequence of the next acknowledgement received on a channel.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	orderedChannelBlocked = prometheus.NewDesc(
+		orderedChannelBlockedMetricName,
+		"Returns for how many seconds next receive sequence of an ordered channel hasn't advanced "+
+			"while packets sent to it are pending, 0 if none are pending.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
 	configMissing = prometheus.NewDesc(
 		configMissingMetricName,
 		"Returns if the rpc config is missing for a channel.",
@@ -58,13 +241,32 @@ var (
 )
 
 type IBCCollector struct {
-	RPCs  *map[string]config.RPC
-	Paths []*config.IBCData
+	RPCs          *map[string]config.RPC
+	Paths         []*config.IBCData
+	ErrorPolicies config.ErrorPolicies
+	Cache         *SampleCache
+	Status        *StatusStore
+	History       *PathHistory
+	Packets       *PacketCache
+	// Ctx bounds queries of a collection, e.g. of a probe, if not nil.
+	Ctx context.Context
 }
 
 func (cc IBCCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- clientExpiry
+	ch <- clientExpiryLastSuccess
+	ch <- clientExpiryThreshold
 	ch <- channelStuckPackets
+	ch <- channelStuckPacketsLastSuccess
+	ch <- clientHeight
+	ch <- clientHeightChanged
+	ch <- clientLastUpdate
+	ch <- clientUpdateInterval
+	ch <- channelStuckPacketsSince
+	ch <- channelNextSequenceSend
+	ch <- channelNextSequenceRecv
+	ch <- channelNextSequenceAck
+	ch <- orderedChannelBlocked
 	ch <- configMissing
 }
 
@@ -77,6 +279,15 @@ func (cc IBCCollector) Collect(ch chan<- prometheus.Metric) {
 		),
 	)
 
+	ctx := collectContext(cc.Ctx)
+
+	names := make([]string, 0, len(cc.Paths))
+	for _, p := range cc.Paths {
+		names = append(names, p.Name())
+	}
+
+	cc.Status.RetainPaths(names)
+
 	var wg sync.WaitGroup
 
 	for _, p := range cc.Paths {
@@ -85,96 +296,208 @@ func (cc IBCCollector) Collect(ch chan<- prometheus.Metric) {
 		go func(path *config.IBCData) {
 			defer wg.Done()
 
-			discordIDs := getDiscordIDs(path.Operators)
-
-			// Client info
-			ci, err := ibc.GetClientsInfo(ctx, path, cc.RPCs)
-			status := successStatus
+			status := GetPathStatus(ctx, path, cc.RPCs, cc.Packets)
+			if status.clientsErr != nil {
+				log.Error(status.clientsErr.Error())
+			}
 
-			if err != nil {
-				status = errorStatus
-				log.Error(err.Error())
+			if status.channelsErr != nil {
+				log.Error(status.channelsErr.Error())
 			}
 
+			cc.Status.UpdatePath(status)
+			cc.collectPath(ch, status)
+		}(p)
+	}
+
+	wg.Wait()
+
+	log.Debug("Stop collecting", zap.String("metric", clientExpiryMetricName))
+}
+
+func (cc IBCCollector) collectPath(ch chan<- prometheus.Metric, status PathStatus) {
+	discordIDs := getDiscordIDs(status.Operators)
+
+	for _, c := range [][2]ChainStatus{
+		{status.Chain1, status.Chain2},
+		{status.Chain2, status.Chain1},
+	} {
+		src, dst := c[0], c[1]
+
+		expiry := 0.0
+		if src.ClientExpiry != nil {
+			expiry = float64(src.ClientExpiry.Unix())
+		}
+
+		labels := []string{
+			src.ChainID,
+			dst.ChainID,
+			src.ChainName,
+			dst.ChainName,
+			src.ClientID,
+			discordIDs,
+		}
+
+		cc.Cache.collect(
+			ch,
+			clientExpiry,
+			clientExpiryLastSuccess,
+			cc.ErrorPolicies.ClientExpiry,
+			expiry,
+			status.clientsErr != nil,
+			labels,
+		)
+
+		if src.ClientExpiryThreshold > 0 {
 			ch <- prometheus.MustNewConstMetric(
-				clientExpiry,
-				prometheus.GaugeValue,
-				float64(ci.ChainAClientExpiration.Unix()),
-				[]string{
-					(*cc.RPCs)[path.Chain1.ChainName].ChainID,
-					(*cc.RPCs)[path.Chain2.ChainName].ChainID,
-					path.Chain1.ChainName,
-					path.Chain2.ChainName,
-					path.Chain1.ClientID,
-					discordIDs,
-					status,
-				}...,
+				clientExpiryThreshold, prometheus.GaugeValue, src.ClientExpiryThreshold.Seconds(), labels...,
 			)
+		}
 
+		cc.collectClientHeight(ch, src, status.clientsErr != nil, labels)
+
+		if src.ClientLastUpdate != nil {
 			ch <- prometheus.MustNewConstMetric(
-				clientExpiry,
-				prometheus.GaugeValue,
-				float64(ci.ChainBClientExpiration.Unix()),
-				[]string{
-					(*cc.RPCs)[path.Chain2.ChainName].ChainID,
-					(*cc.RPCs)[path.Chain1.ChainName].ChainID,
-					path.Chain2.ChainName,
-					path.Chain1.ChainName,
-					path.Chain2.ClientID,
-					discordIDs,
-					status,
-				}...,
+				clientLastUpdate, prometheus.GaugeValue, float64(src.ClientLastUpdate.Unix()), labels...,
 			)
+		}
 
-			// Stuck packets
-			status = successStatus
+		if src.ClientUpdateInterval > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				clientUpdateInterval, prometheus.GaugeValue, src.ClientUpdateInterval.Seconds(), labels...,
+			)
+		}
+	}
 
-			stuckPackets, err := ibc.GetChannelsInfo(ctx, path, cc.RPCs)
-			if err != nil {
-				status = errorStatus
+	for _, sp := range status.Channels {
+		ends := []struct {
+			src, dst                 ChainStatus
+			srcChan, dstChan         string
+			stuck, timedOut, unknown int
+			sequences, dstSequences  *ibc.Sequences
+		}{
+			{
+				status.Chain1, status.Chain2, sp.SrcChannelID, sp.DstChannelID,
+				sp.SrcStuckPackets, sp.SrcTimedOutPackets, sp.SrcUnknownPackets,
+				sp.SrcNextSequences, sp.DstNextSequences,
+			},
+			{
+				status.Chain2, status.Chain1, sp.DstChannelID, sp.SrcChannelID,
+				sp.DstStuckPackets, sp.DstTimedOutPackets, sp.DstUnknownPackets,
+				sp.DstNextSequences, sp.SrcNextSequences,
+			},
+		}
 
-				log.Error(err.Error())
+		for _, e := range ends {
+			labels := []string{
+				e.srcChan,
+				e.dstChan,
+				e.src.ChainID,
+				e.dst.ChainID,
+				e.src.ChainName,
+				e.dst.ChainName,
+				discordIDs,
 			}
 
-			if !reflect.DeepEqual(stuckPackets, ibc.ChannelsInfo{}) {
-				for _, sp := range stuckPackets.Channels {
-					ch <- prometheus.MustNewConstMetric(
-						channelStuckPackets,
-						prometheus.GaugeValue,
-						float64(sp.StuckPackets.Source),
-						[]string{
-							sp.Source,
-							sp.Destination,
-							(*cc.RPCs)[path.Chain1.ChainName].ChainID,
-							(*cc.RPCs)[path.Chain2.ChainName].ChainID,
-							path.Chain1.ChainName,
-							path.Chain2.ChainName,
-							discordIDs,
-							status,
-						}...,
-					)
-
-					ch <- prometheus.MustNewConstMetric(
-						channelStuckPackets,
-						prometheus.GaugeValue,
-						float64(sp.StuckPackets.Destination),
-						[]string{
-							sp.Destination,
-							sp.Source,
-							(*cc.RPCs)[path.Chain2.ChainName].ChainID,
-							(*cc.RPCs)[path.Chain1.ChainName].ChainID,
-							path.Chain2.ChainName,
-							path.Chain1.ChainName,
-							discordIDs,
-							status,
-						}...,
-					)
-				}
+			cc.Cache.collectStates(
+				ch,
+				channelStuckPackets,
+				channelStuckPacketsLastSuccess,
+				cc.ErrorPolicies.StuckPackets,
+				packetStates,
+				map[string]float64{
+					packetStateRelayable: float64(e.stuck - e.timedOut - e.unknown),
+					packetStateTimedOut:  float64(e.timedOut),
+					packetStateUnknown:   float64(e.unknown),
+				},
+				status.channelsErr != nil,
+				labels,
+			)
+
+			cc.collectStuckSince(ch, ChannelKey(e.src.ChainName, e.srcChan), e.stuck, status.channelsErr != nil, labels)
+
+			if e.sequences != nil {
+				ch <- prometheus.MustNewConstMetric(
+					channelNextSequenceSend, prometheus.GaugeValue, float64(e.sequences.Send), labels...,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					channelNextSequenceRecv, prometheus.GaugeValue, float64(e.sequences.Recv), labels...,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					channelNextSequenceAck, prometheus.GaugeValue, float64(e.sequences.Ack), labels...,
+				)
 			}
-		}(p)
+
+			if sp.Ordered() && e.dstSequences != nil {
+				cc.collectOrderedBlocked(ch, ChannelKey(e.dst.ChainName, e.dstChan), e.dstSequences.Recv, e.stuck, labels)
+			}
+		}
 	}
+}
 
-	wg.Wait()
+// collectClientHeight exports latest height of client with time it was
+// first seen. Last seen height is exported when the query failed.
+func (cc IBCCollector) collectClientHeight(ch chan<- prometheus.Metric, src ChainStatus, failed bool, labels []string) {
+	key := clientKey(src.ChainName, src.ClientID)
 
-	log.Debug("Stop collecting", zap.String("metric", clientExpiryMetricName))
+	height, ok := cc.History.ClientHeight(key)
+
+	if !failed && src.ClientHeight > 0 {
+		var err error
+
+		height, err = cc.History.UpdateClientHeight(key, src.ClientHeight)
+		if err != nil {
+			log.Error("Failed to store client height", zap.Error(err))
+		}
+
+		ok = true
+	}
+
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(clientHeight, prometheus.GaugeValue, float64(height.Height), labels...)
+
+	if !height.ChangedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			clientHeightChanged, prometheus.GaugeValue, float64(height.ChangedAt.Unix()), labels...,
+		)
+	}
+}
+
+// collectStuckSince exports since when channel end has stuck packets. The
+// recorded time is kept when the query failed.
+func (cc IBCCollector) collectStuckSince(
+	ch chan<- prometheus.Metric, key string, stuck int, failed bool, labels []string,
+) {
+	since := cc.History.StuckSince(key)
+
+	if !failed {
+		var err error
+
+		since, err = cc.History.UpdateStuckPackets(key, stuck)
+		if err != nil {
+			log.Error("Failed to store stuck packets start", zap.Error(err))
+		}
+	}
+
+	if since != nil {
+		ch <- prometheus.MustNewConstMetric(
+			channelStuckPacketsSince, prometheus.GaugeValue, float64(since.Unix()), labels...,
+		)
+	}
+}
+
+// collectOrderedBlocked exports how long next receive sequence of ordered
+// channel end with key hasn't advanced while stuck packets are sent to it.
+func (cc IBCCollector) collectOrderedBlocked(
+	ch chan<- prometheus.Metric, key string, nextRecv uint64, stuck int, labels []string,
+) {
+	blocked, err := cc.History.UpdateNextRecv(key, nextRecv, stuck > 0)
+	if err != nil {
+		log.Error("Failed to store next receive sequence", zap.Error(err))
+	}
+
+	ch <- prometheus.MustNewConstMetric(orderedChannelBlocked, prometheus.GaugeValue, blocked.Seconds(), labels...)
 }
@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	icaChannelStateMetricName   = "cosmos_ibc_ica_channel_state"
+	icaPendingPacketsMetricName = "cosmos_ibc_ica_pending_packets"
+	icaClosedMetricName         = "cosmos_ibc_ica_closed"
+)
+
+var (
+	icaChannelState = prometheus.NewDesc(
+		icaChannelStateMetricName,
+		"Returns 1 for the current state of an interchain account channel.",
+		[]string{
+			"controller_chain_id",
+			"host_chain_id",
+			"controller_chain_name",
+			"host_chain_name",
+			"connection_id",
+			"owner",
+			"controller_channel_id",
+			"host_channel_id",
+			"state",
+		},
+		nil,
+	)
+	icaPendingPackets = prometheus.NewDesc(
+		icaPendingPacketsMetricName,
+		"Returns packets sent on an open interchain account channel which haven't been relayed yet.",
+		[]string{
+			"controller_chain_id",
+			"host_chain_id",
+			"controller_chain_name",
+			"host_chain_name",
+			"connection_id",
+			"owner",
+			"controller_channel_id",
+			"host_channel_id",
+		},
+		nil,
+	)
+	icaClosed = prometheus.NewDesc(
+		icaClosedMetricName,
+		"Returns 1 if all channels of an interchain account are closed and it needs to be reopened.",
+		[]string{
+			"controller_chain_id",
+			"host_chain_id",
+			"controller_chain_name",
+			"host_chain_name",
+			"connection_id",
+			"owner",
+		},
+		nil,
+	)
+)
+
+type icaQuery func(ctx context.Context, path *config.IBCData, rpcs *map[string]config.RPC) ([]ibc.ICAChannel, error)
+
+// ICACollector exports state of interchain account channels opened on
+// connections of paths with ICA channels in the registry.
+type ICACollector struct {
+	RPCs  *map[string]config.RPC
+	Paths []*config.IBCData
+
+	query icaQuery
+}
+
+func (ic ICACollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- icaChannelState
+	ch <- icaPendingPackets
+	ch <- icaClosed
+}
+
+func (ic ICACollector) Collect(ch chan<- prometheus.Metric) {
+	log.Debug("Start collecting", zap.String("metric", icaChannelStateMetricName))
+
+	query := ic.query
+	if query == nil {
+		query = ibc.GetICAChannels
+	}
+
+	var wg sync.WaitGroup
+
+	for _, p := range ic.Paths {
+		wg.Add(1)
+
+		go func(path *config.IBCData) {
+			defer wg.Done()
+
+			channels, err := query(ctx, path, ic.RPCs)
+			if err != nil {
+				log.Error(err.Error(), zap.String("path", path.Name()))
+			}
+
+			ic.collectChannels(ch, channels)
+		}(p)
+	}
+
+	wg.Wait()
+
+	log.Debug("Stop collecting", zap.String("metric", icaChannelStateMetricName))
+}
+
+func (ic ICACollector) collectChannels(ch chan<- prometheus.Metric, channels []ibc.ICAChannel) {
+	// Interchain accounts are identified by controller connection and owner.
+	type account struct {
+		controller, host, connectionID, owner string
+	}
+
+	// Accounts with a channel which isn't closed.
+	active := map[account]bool{}
+	accounts := []account{}
+
+	for _, c := range channels {
+		labels := []string{
+			(*ic.RPCs)[c.ControllerChainName].ChainID,
+			(*ic.RPCs)[c.HostChainName].ChainID,
+			c.ControllerChainName,
+			c.HostChainName,
+			c.ConnectionID,
+			c.Owner,
+			c.ChannelID,
+			c.HostChannelID,
+		}
+
+		ch <- prometheus.MustNewConstMetric(icaChannelState, prometheus.GaugeValue, 1, append(labels, c.State)...)
+
+		if c.State == ibc.StateName(chantypes.OPEN) {
+			ch <- prometheus.MustNewConstMetric(
+				icaPendingPackets, prometheus.GaugeValue, float64(c.PendingPackets), labels...,
+			)
+		}
+
+		a := account{c.ControllerChainName, c.HostChainName, c.ConnectionID, c.Owner}
+		if _, ok := active[a]; !ok {
+			accounts = append(accounts, a)
+		}
+
+		active[a] = active[a] || c.State != ibc.StateName(chantypes.CLOSED)
+	}
+
+	for _, a := range accounts {
+		closed := 0.0
+		if !active[a] {
+			closed = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			icaClosed, prometheus.GaugeValue, closed,
+			(*ic.RPCs)[a.controller].ChainID, (*ic.RPCs)[a.host].ChainID, a.controller, a.host, a.connectionID, a.owner,
+		)
+	}
+}
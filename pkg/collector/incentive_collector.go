@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	incentivizedPacketsMetricName = "cosmos_ibc_fee_incentivized_packets"
+	incentivesMetricName          = "cosmos_ibc_fee_incentives"
+	payeeRegisteredMetricName     = "cosmos_ibc_fee_payee_registered"
+
+	feeTypeRecv    = "recv"
+	feeTypeAck     = "ack"
+	feeTypeTimeout = "timeout"
+
+	payeeTypePayee             = "payee"
+	payeeTypeCounterpartyPayee = "counterparty_payee"
+)
+
+var (
+	incentivizedPackets = prometheus.NewDesc(
+		incentivizedPacketsMetricName,
+		"Returns packets sent from a fee-enabled channel with outstanding ICS-29 fees.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+		},
+		nil,
+	)
+	incentives = prometheus.NewDesc(
+		incentivesMetricName,
+		"Returns total outstanding ICS-29 fees of packets sent from a channel by fee type, recv, ack or timeout.",
+		[]string{
+			"src_channel_id",
+			"dst_channel_id",
+			"src_chain_id",
+			"dst_chain_id",
+			"src_chain_name",
+			"dst_chain_name",
+			"discord_ids",
+			"denom",
+			"base_denom",
+			"trace_path",
+			"fee_type",
+		},
+		nil,
+	)
+	payeeRegistered = prometheus.NewDesc(
+		payeeRegisteredMetricName,
+		"Returns 1 if an operator registered ICS-29 payee of a type, payee or counterparty_payee, on a channel.",
+		[]string{"chain_id", "chain_name", "channel_id", "operator", "address", "payee_type"},
+		nil,
+	)
+)
+
+type feeInfoQuery func(ctx context.Context, path *config.IBCData, rpcs *map[string]config.RPC) (ibc.FeeInfo, error)
+
+// IncentiveCollector exports ICS-29 fees outstanding on fee-enabled
+// channels and whether path operators registered payees on them.
+type IncentiveCollector struct {
+	RPCs   *map[string]config.RPC
+	Paths  []*config.IBCData
+	Denoms *DenomCache
+
+	query feeInfoQuery
+}
+
+func (ic IncentiveCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- incentivizedPackets
+	ch <- incentives
+	ch <- payeeRegistered
+}
+
+func (ic IncentiveCollector) Collect(ch chan<- prometheus.Metric) {
+	log.Debug("Start collecting", zap.String("metric", incentivesMetricName))
+
+	query := ic.query
+	if query == nil {
+		query = ibc.GetFeeInfo
+	}
+
+	var wg sync.WaitGroup
+
+	for _, p := range ic.Paths {
+		wg.Add(1)
+
+		go func(path *config.IBCData) {
+			defer wg.Done()
+
+			info, err := query(ctx, path, ic.RPCs)
+			if err != nil {
+				log.Error(err.Error(), zap.String("path", path.Name()))
+			}
+
+			ic.collectPath(ch, path, info)
+		}(p)
+	}
+
+	wg.Wait()
+
+	log.Debug("Stop collecting", zap.String("metric", incentivesMetricName))
+}
+
+func (ic IncentiveCollector) collectPath(ch chan<- prometheus.Metric, path *config.IBCData, info ibc.FeeInfo) {
+	discordIDs := getDiscordIDs(path.Operators)
+
+	for _, i := range info.Incentives {
+		labels := []string{
+			i.ChannelID,
+			i.CounterpartyChannelID,
+			(*ic.RPCs)[i.ChainName].ChainID,
+			(*ic.RPCs)[i.CounterpartyChainName].ChainID,
+			i.ChainName,
+			i.CounterpartyChainName,
+			discordIDs,
+		}
+
+		ch <- prometheus.MustNewConstMetric(incentivizedPackets, prometheus.GaugeValue, float64(i.Packets), labels...)
+
+		for _, fee := range []struct {
+			feeType string
+			coins   sdk.Coins
+		}{
+			{feeTypeRecv, i.RecvFee},
+			{feeTypeAck, i.AckFee},
+			{feeTypeTimeout, i.TimeoutFee},
+		} {
+			for _, coin := range fee.coins {
+				trace, err := ic.Denoms.Trace(ctx, (*ic.RPCs)[i.ChainName], coin.Denom)
+				if err != nil {
+					log.Error(err.Error(), zap.String("denom", coin.Denom))
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					incentives, prometheus.GaugeValue, intToFloat(coin.Amount),
+					append(labels, coin.Denom, trace.BaseDenom, trace.TracePath, fee.feeType)...,
+				)
+			}
+		}
+	}
+
+	for _, p := range info.Payees {
+		for _, payee := range []struct {
+			payeeType string
+			address   string
+		}{
+			{payeeTypePayee, p.Payee},
+			{payeeTypeCounterpartyPayee, p.CounterpartyPayee},
+		} {
+			registered := 0.0
+			if payee.address != "" {
+				registered = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				payeeRegistered, prometheus.GaugeValue, registered,
+				(*ic.RPCs)[p.ChainName].ChainID, p.ChainName, p.ChannelID, p.Operator, p.Address, payee.payeeType,
+			)
+		}
+	}
+}
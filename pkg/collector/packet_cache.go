@@ -0,0 +1,199 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+)
+
+// ChannelPackets lists stuck packets sent from a channel end.
+type ChannelPackets struct {
+	Path                  string       `json:"path"`
+	ChainName             string       `json:"chain_name"`
+	ChainID               string       `json:"chain_id"`
+	ChannelID             string       `json:"channel_id"`
+	PortID                string       `json:"port_id"`
+	CounterpartyChainName string       `json:"counterparty_chain_name"`
+	CounterpartyChannelID string       `json:"counterparty_channel_id"`
+	Packets               []ibc.Packet `json:"packets"`
+}
+
+// packetQuery returns metadata of packets with sequences sent from channel
+// end on chain with rpc.
+type packetQuery func(ctx context.Context, rpc config.RPC, channelID, portID string, sequences []uint64) []ibc.Packet
+
+// packetRetryBackoff is how long packets failed to query are reported unknown
+// before they're queried again.
+const packetRetryBackoff = 5 * time.Minute
+
+// failedPacket is a packet failed to query at time.
+type failedPacket struct {
+	packet ibc.Packet
+	at     time.Time
+}
+
+// PacketCache keeps metadata of stuck packets by sequence. Metadata is
+// queried once per packet when it is first seen stuck and dropped once the
+// packet is relayed. At most ibc.MaxPacketLookups packets are queried per
+// channel end and lookup, failed ones are retried after packetRetryBackoff.
+type PacketCache struct {
+	mu      sync.Mutex
+	packets map[string]map[uint64]ibc.Packet
+	failed  map[string]map[uint64]failedPacket
+	query   packetQuery
+	now     func() time.Time
+}
+
+func NewPacketCache() *PacketCache {
+	return &PacketCache{
+		packets: map[string]map[uint64]ibc.Packet{},
+		failed:  map[string]map[uint64]failedPacket{},
+		query:   queryPackets,
+		now:     time.Now,
+	}
+}
+
+func queryPackets(ctx context.Context, rpc config.RPC, channelID, portID string, sequences []uint64) []ibc.Packet {
+	c, err := chain.PrepChain(ctx, chain.Info{
+		ChainID: rpc.ChainID,
+		RPCAddr: rpc.URL,
+		Timeout: rpc.Timeout,
+	})
+	if err != nil {
+		packets := make([]ibc.Packet, 0, len(sequences))
+		for _, seq := range sequences {
+			packets = append(packets, ibc.Packet{Sequence: seq, Error: fmt.Sprintf("%v for %s", err, rpc.ChainID)})
+		}
+
+		return packets
+	}
+
+	return ibc.GetPackets(ctx, c, channelID, portID, sequences)
+}
+
+// StuckPackets returns stuck packets of path channel ends with metadata.
+// Only channel ends with stuck packets are returned, filtered by channelID
+// of either end when it is not empty.
+func (c *PacketCache) StuckPackets(
+	ctx context.Context, rpcs *map[string]config.RPC, status PathStatus, channelID string,
+) []ChannelPackets {
+	res := []ChannelPackets{}
+
+	for _, ch := range status.Channels {
+		if channelID != "" && channelID != ch.SrcChannelID && channelID != ch.DstChannelID {
+			continue
+		}
+
+		ends := []struct {
+			src, dst         ChainStatus
+			srcChan, dstChan string
+			srcPort          string
+			sequences        []uint64
+		}{
+			{status.Chain1, status.Chain2, ch.SrcChannelID, ch.DstChannelID, ch.SrcPortID, ch.SrcSequences},
+			{status.Chain2, status.Chain1, ch.DstChannelID, ch.SrcChannelID, ch.DstPortID, ch.DstSequences},
+		}
+
+		for _, e := range ends {
+			if len(e.sequences) == 0 {
+				continue
+			}
+
+			res = append(res, ChannelPackets{
+				Path:                  status.Name,
+				ChainName:             e.src.ChainName,
+				ChainID:               e.src.ChainID,
+				ChannelID:             e.srcChan,
+				PortID:                e.srcPort,
+				CounterpartyChainName: e.dst.ChainName,
+				CounterpartyChannelID: e.dstChan,
+				Packets:               c.Lookup(ctx, (*rpcs)[e.src.ChainName], e.srcChan, e.srcPort, e.sequences),
+			})
+		}
+	}
+
+	return res
+}
+
+// Lookup returns packets with sequences sent from channel of chain with
+// rpc from cache, querying missing ones. sequences must be all stuck packets
+// of the channel, cached packets not stuck anymore are dropped. Packets not
+// queried over the limit or failed within backoff have Error set.
+func (c *PacketCache) Lookup(
+	ctx context.Context, rpc config.RPC, channelID, portID string, sequences []uint64,
+) []ibc.Packet {
+	key := ChannelKey(rpc.ChainName, channelID)
+	now := c.now()
+
+	c.mu.Lock()
+	cached := c.packets[key]
+	failed := c.failed[key]
+	c.mu.Unlock()
+
+	missing := []uint64{}
+
+	for _, seq := range sequences {
+		if _, ok := cached[seq]; ok {
+			continue
+		}
+
+		if f, ok := failed[seq]; ok && now.Sub(f.at) < packetRetryBackoff {
+			continue
+		}
+
+		missing = append(missing, seq)
+	}
+
+	// Remaining packets are queried by later lookups.
+	if len(missing) > ibc.MaxPacketLookups {
+		missing = missing[:ibc.MaxPacketLookups]
+	}
+
+	queried := map[uint64]ibc.Packet{}
+
+	if len(missing) > 0 {
+		for _, p := range c.query(ctx, rpc, channelID, portID, missing) {
+			queried[p.Sequence] = p
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	packets := make([]ibc.Packet, 0, len(sequences))
+	stuck := make(map[uint64]ibc.Packet, len(sequences))
+	stillFailed := map[uint64]failedPacket{}
+
+	for _, seq := range sequences {
+		p, ok := cached[seq]
+		if !ok {
+			p, ok = queried[seq]
+		}
+
+		switch {
+		case !ok:
+			if f, failedBefore := failed[seq]; failedBefore {
+				p = f.packet
+				stillFailed[seq] = f
+			} else {
+				p = ibc.Packet{Sequence: seq, Error: ibc.ErrPacketLookupSkipped}
+			}
+		case p.Error != "":
+			stillFailed[seq] = failedPacket{packet: p, at: now}
+		default:
+			stuck[seq] = p
+		}
+
+		packets = append(packets, p)
+	}
+
+	c.packets[key] = stuck
+	c.failed[key] = stillFailed
+
+	return packets
+}
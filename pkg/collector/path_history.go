@@ -0,0 +1,217 @@
+package collector
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/store"
+)
+
+const (
+	stuckSinceBucket    = "stuck_since"
+	clientHeightsBucket = "client_heights"
+	nextRecvBucket      = "next_recv"
+)
+
+// ClientHeight is the last seen latest height of a light client.
+type ClientHeight struct {
+	Height uint64 `json:"height"`
+	// ChangedAt is when the height was seen for the first time.
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// nextRecv is the last seen next receive sequence of an ordered channel end
+// while packets were pending.
+type nextRecv struct {
+	Sequence uint64 `json:"sequence"`
+	// Since is when the sequence was seen for the first time.
+	Since time.Time `json:"since"`
+}
+
+// PathHistory keeps since when channel ends have stuck packets, last seen
+// heights of clients and next receive sequences of ordered channels. It
+// outlives collectors and is persisted to store, so durations survive
+// restarts.
+type PathHistory struct {
+	mu         sync.Mutex
+	store      *store.Store
+	stuckSince map[string]time.Time
+	clients    map[string]ClientHeight
+	nextRecv   map[string]nextRecv
+	now        func() time.Time
+}
+
+// NewPathHistory returns history loaded from s, which may be nil.
+func NewPathHistory(s *store.Store) (*PathHistory, error) {
+	h := &PathHistory{
+		store:      s,
+		stuckSince: map[string]time.Time{},
+		clients:    map[string]ClientHeight{},
+		nextRecv:   map[string]nextRecv{},
+		now:        time.Now,
+	}
+
+	err := s.ForEach(stuckSinceBucket, func(key string, value []byte) error {
+		since := time.Time{}
+		if err := json.Unmarshal(value, &since); err != nil {
+			return err
+		}
+
+		h.stuckSince[key] = since
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.ForEach(clientHeightsBucket, func(key string, value []byte) error {
+		height := ClientHeight{}
+		if err := json.Unmarshal(value, &height); err != nil {
+			return err
+		}
+
+		h.clients[key] = height
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.ForEach(nextRecvBucket, func(key string, value []byte) error {
+		recv := nextRecv{}
+		if err := json.Unmarshal(value, &recv); err != nil {
+			return err
+		}
+
+		h.nextRecv[key] = recv
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// ChannelKey identifies channel end of chainName in history.
+func ChannelKey(chainName, channelID string) string {
+	return chainName + "/" + channelID
+}
+
+func clientKey(chainName, clientID string) string {
+	return chainName + "/" + clientID
+}
+
+// UpdateStuckPackets records stuck packets count of channel end with key and
+// returns since when it has stuck packets, nil if it has none.
+func (h *PathHistory) UpdateStuckPackets(key string, stuck int) (*time.Time, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	since, ok := h.stuckSince[key]
+
+	switch {
+	case stuck == 0 && ok:
+		delete(h.stuckSince, key)
+
+		return nil, h.store.Delete(stuckSinceBucket, key)
+	case stuck == 0:
+		return nil, nil
+	case ok:
+		return &since, nil
+	}
+
+	since = h.now()
+	h.stuckSince[key] = since
+
+	return &since, h.store.Put(stuckSinceBucket, key, since)
+}
+
+// StuckSince returns since when channel end with key has stuck packets, nil
+// if it has none.
+func (h *PathHistory) StuckSince(key string) *time.Time {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if since, ok := h.stuckSince[key]; ok {
+		return &since
+	}
+
+	return nil
+}
+
+// UpdateClientHeight records latest height of client with key and returns
+// it with time it was seen first.
+func (h *PathHistory) UpdateClientHeight(key string, height uint64) (ClientHeight, error) {
+	if h == nil {
+		return ClientHeight{Height: height}, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if prev, ok := h.clients[key]; ok && prev.Height == height {
+		return prev, nil
+	}
+
+	ch := ClientHeight{Height: height, ChangedAt: h.now()}
+	h.clients[key] = ch
+
+	return ch, h.store.Put(clientHeightsBucket, key, ch)
+}
+
+// ClientHeight returns the last seen height of client with key.
+func (h *PathHistory) ClientHeight(key string) (ClientHeight, bool) {
+	if h == nil {
+		return ClientHeight{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch, ok := h.clients[key]
+
+	return ch, ok
+}
+
+// UpdateNextRecv records next receive sequence of ordered channel end with
+// key, which has pending packets sent by counterparty, and returns how long
+// the sequence hasn't advanced. Zero is returned when nothing is pending.
+func (h *PathHistory) UpdateNextRecv(key string, sequence uint64, pending bool) (time.Duration, error) {
+	if h == nil {
+		return 0, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev, ok := h.nextRecv[key]
+
+	switch {
+	case !pending && ok:
+		delete(h.nextRecv, key)
+
+		return 0, h.store.Delete(nextRecvBucket, key)
+	case !pending:
+		return 0, nil
+	case ok && prev.Sequence == sequence:
+		return h.now().Sub(prev.Since), nil
+	}
+
+	recv := nextRecv{Sequence: sequence, Since: h.now()}
+	h.nextRecv[key] = recv
+
+	return 0, h.store.Put(nextRecvBucket, key, recv)
+}
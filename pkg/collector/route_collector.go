@@ -0,0 +1,283 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	routeClientExpiryMetricName = "cosmos_ibc_route_client_expiry"
+	routeStuckPacketsMetricName = "cosmos_ibc_route_stuck_packets"
+	routeSlowestHopMetricName   = "cosmos_ibc_route_slowest_hop_stuck_seconds"
+	routeDegradedMetricName     = "cosmos_ibc_route_degraded"
+	routeHopDegradedMetricName  = "cosmos_ibc_route_hop_degraded"
+)
+
+// Reasons of degraded route hops.
+const (
+	hopPathMissing     = "path not found in registry"
+	hopNotCollected    = "path not collected yet"
+	hopClientExpired   = "client expired"
+	hopClientExpiring  = "client expires within threshold"
+	hopStuckPackets    = "%d stuck packets"
+	hopCollectionError = "collection error: %s"
+)
+
+var (
+	routeClientExpiry = prometheus.NewDesc(
+		routeClientExpiryMetricName,
+		"Returns the earliest light client expiry of route hops in unixtime.",
+		[]string{"route"},
+		nil,
+	)
+	routeStuckPackets = prometheus.NewDesc(
+		routeStuckPacketsMetricName,
+		"Returns total stuck packets sent in transfer direction on transfer channels of route hops.",
+		[]string{"route"},
+		nil,
+	)
+	routeSlowestHop = prometheus.NewDesc(
+		routeSlowestHopMetricName,
+		"Returns for how long the route hop with the oldest stuck packets has had them, in seconds.",
+		[]string{"route", "hop"},
+		nil,
+	)
+	routeDegraded = prometheus.NewDesc(
+		routeDegradedMetricName,
+		"Returns 1 if any hop of the route is degraded.",
+		[]string{"route"},
+		nil,
+	)
+	routeHopDegraded = prometheus.NewDesc(
+		routeHopDegradedMetricName,
+		"Returns 1 if a route hop is degraded, e.g. has stuck packets or an expiring client.",
+		[]string{"route", "hop", "src_chain_name", "dst_chain_name"},
+		nil,
+	)
+)
+
+// HopStatus is the state of a route hop built from status of its registry
+// path.
+type HopStatus struct {
+	Path         string     `json:"path"`
+	SrcChainName string     `json:"src_chain_name"`
+	DstChainName string     `json:"dst_chain_name"`
+	ClientExpiry *time.Time `json:"client_expiry"`
+	// StuckPackets are packets sent from source chain on transfer channels.
+	StuckPackets int `json:"stuck_packets"`
+	// StuckSince is since when the hop has stuck packets, nil if unknown.
+	StuckSince *time.Time `json:"stuck_since,omitempty"`
+	Degraded   bool       `json:"degraded"`
+	Reasons    []string   `json:"reasons,omitempty"`
+}
+
+// RouteStatus is the aggregated state of a multi-hop packet forward route.
+type RouteStatus struct {
+	Name         string     `json:"name"`
+	Chains       []string   `json:"chains"`
+	ClientExpiry *time.Time `json:"client_expiry"`
+	StuckPackets int        `json:"stuck_packets"`
+	// SlowestHop is the path of the hop with the oldest stuck packets, empty
+	// if no hop has them.
+	SlowestHop string      `json:"slowest_hop,omitempty"`
+	Degraded   bool        `json:"degraded"`
+	Hops       []HopStatus `json:"hops"`
+
+	// slowestHop is index of the slowest hop, -1 if none.
+	slowestHop int
+}
+
+// GetRouteStatus aggregates status of route hops from the latest collected
+// status of registry paths. It queries no chains.
+func GetRouteStatus(
+	route *config.ForwardRoute, paths []*config.IBCData, store *StatusStore, history *PathHistory, now time.Time,
+) RouteStatus {
+	status := RouteStatus{
+		Name:   route.Name,
+		Chains: route.Chains,
+		Hops:   []HopStatus{},
+
+		slowestHop: -1,
+	}
+
+	var slowest *time.Time
+
+	for _, h := range route.Hops() {
+		hop := getHopStatus(h, paths, store, history, now)
+
+		if hop.ClientExpiry != nil && (status.ClientExpiry == nil || hop.ClientExpiry.Before(*status.ClientExpiry)) {
+			status.ClientExpiry = hop.ClientExpiry
+		}
+
+		if hop.StuckSince != nil && (slowest == nil || hop.StuckSince.Before(*slowest)) {
+			slowest = hop.StuckSince
+			status.SlowestHop = hop.Path
+			status.slowestHop = len(status.Hops)
+		}
+
+		status.StuckPackets += hop.StuckPackets
+		status.Degraded = status.Degraded || hop.Degraded
+		status.Hops = append(status.Hops, hop)
+	}
+
+	return status
+}
+
+func getHopStatus(
+	hop config.Hop, paths []*config.IBCData, store *StatusStore, history *PathHistory, now time.Time,
+) HopStatus {
+	status := HopStatus{Path: hop.Path(), SrcChainName: hop.Src, DstChainName: hop.Dst}
+
+	path := findPath(paths, hop.Src, hop.Dst)
+	if path == nil {
+		status.Degraded = true
+		status.Reasons = append(status.Reasons, hopPathMissing)
+
+		return status
+	}
+
+	status.Path = path.Name()
+
+	ps, ok := store.Path(path.Name())
+	if !ok {
+		status.Degraded = true
+		status.Reasons = append(status.Reasons, hopNotCollected)
+
+		return status
+	}
+
+	if ps.LastError != "" {
+		status.Reasons = append(status.Reasons, fmt.Sprintf(hopCollectionError, ps.LastError))
+	}
+
+	status.ClientExpiry = ps.MinClientExpiry()
+
+	for _, c := range []ChainStatus{ps.Chain1, ps.Chain2} {
+		switch {
+		case c.ClientExpiry == nil:
+		case !now.Before(*c.ClientExpiry):
+			status.Reasons = append(status.Reasons, hopClientExpired)
+		case c.ClientExpiryThreshold != 0 && c.ClientExpiry.Sub(now) < c.ClientExpiryThreshold:
+			status.Reasons = append(status.Reasons, hopClientExpiring)
+		}
+	}
+
+	for _, c := range ps.Channels {
+		if c.SrcPortID != transfertypes.PortID || c.DstPortID != transfertypes.PortID {
+			continue
+		}
+
+		stuck, channelID := c.SrcStuckPackets, c.SrcChannelID
+		if ps.Chain2.ChainName == hop.Src {
+			stuck, channelID = c.DstStuckPackets, c.DstChannelID
+		}
+
+		if stuck == 0 {
+			continue
+		}
+
+		status.StuckPackets += stuck
+
+		since := history.StuckSince(ChannelKey(hop.Src, channelID))
+		if since != nil && (status.StuckSince == nil || since.Before(*status.StuckSince)) {
+			status.StuckSince = since
+		}
+	}
+
+	if status.StuckPackets > 0 {
+		status.Reasons = append(status.Reasons, fmt.Sprintf(hopStuckPackets, status.StuckPackets))
+	}
+
+	status.Degraded = len(status.Reasons) > 0
+
+	return status
+}
+
+// findPath returns path between chains given in any order or nil if there
+// is none.
+func findPath(paths []*config.IBCData, chain1, chain2 string) *config.IBCData {
+	for _, p := range paths {
+		if (p.Chain1.ChainName == chain1 && p.Chain2.ChainName == chain2) ||
+			(p.Chain1.ChainName == chain2 && p.Chain2.ChainName == chain1) {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// RouteCollector exports aggregated health of configured packet forward
+// routes from path status stored by the IBC collector, so values lag behind
+// path metrics by up to one collection.
+type RouteCollector struct {
+	Routes  []*config.ForwardRoute
+	Paths   []*config.IBCData
+	Status  *StatusStore
+	History *PathHistory
+
+	now func() time.Time
+}
+
+func (rc RouteCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- routeClientExpiry
+	ch <- routeStuckPackets
+	ch <- routeSlowestHop
+	ch <- routeDegraded
+	ch <- routeHopDegraded
+}
+
+func (rc RouteCollector) Collect(ch chan<- prometheus.Metric) {
+	log.Debug("Start collecting", zap.String("metric", routeDegradedMetricName))
+
+	now := time.Now()
+	if rc.now != nil {
+		now = rc.now()
+	}
+
+	for _, r := range rc.Routes {
+		status := GetRouteStatus(r, rc.Paths, rc.Status, rc.History, now)
+
+		if status.ClientExpiry != nil {
+			ch <- prometheus.MustNewConstMetric(
+				routeClientExpiry, prometheus.GaugeValue, float64(status.ClientExpiry.Unix()), status.Name,
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			routeStuckPackets, prometheus.GaugeValue, float64(status.StuckPackets), status.Name,
+		)
+
+		degraded := 0.0
+		if status.Degraded {
+			degraded = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(routeDegraded, prometheus.GaugeValue, degraded, status.Name)
+
+		for i, h := range status.Hops {
+			if i == status.slowestHop && h.StuckSince != nil {
+				ch <- prometheus.MustNewConstMetric(
+					routeSlowestHop, prometheus.GaugeValue, now.Sub(*h.StuckSince).Seconds(), status.Name, h.Path,
+				)
+			}
+
+			degraded := 0.0
+			if h.Degraded {
+				degraded = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				routeHopDegraded, prometheus.GaugeValue, degraded, status.Name, h.Path, h.SrcChainName, h.DstChainName,
+			)
+		}
+	}
+
+	log.Debug("Stop collecting", zap.String("metric", routeDegradedMetricName))
+}
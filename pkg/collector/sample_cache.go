@@ -0,0 +1,166 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+type sample struct {
+	value     float64
+	timestamp time.Time
+}
+
+// SampleCache keeps the last successfully collected value of each series.
+// It outlives collectors, which are recreated on every configuration refresh.
+// A nil cache keeps no samples, so only fresh samples are exported and failed
+// series are omitted whatever the error policy.
+type SampleCache struct {
+	mu      sync.Mutex
+	samples map[string]sample
+	now     func() time.Time
+}
+
+func NewSampleCache() *SampleCache {
+	return &SampleCache{
+		samples: map[string]sample{},
+		now:     time.Now,
+	}
+}
+
+func sampleKey(desc *prometheus.Desc, labels []string) string {
+	return desc.String() + "\xff" + strings.Join(labels, "\xff")
+}
+
+func (c *SampleCache) store(desc *prometheus.Desc, labels []string, value float64) sample {
+	if c == nil {
+		return sample{value: value, timestamp: time.Now()}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := sample{value: value, timestamp: c.now()}
+	c.samples[sampleKey(desc, labels)] = s
+
+	return s
+}
+
+func (c *SampleCache) load(desc *prometheus.Desc, labels []string) (sample, bool) {
+	if c == nil {
+		return sample{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.samples[sampleKey(desc, labels)]
+
+	return s, ok
+}
+
+// collect sends the sample for a series according to the error policy.
+// On success value is exported with a success status and cached. On failure
+// the cached value is exported with an error status when the policy is
+// ErrorPolicyLastSuccess, otherwise the sample is omitted. The last success
+// timestamp is exported whenever a successful value has been seen.
+// labels must not contain the status label, which is appended here.
+func (c *SampleCache) collect(
+	ch chan<- prometheus.Metric,
+	desc, lastSuccessDesc *prometheus.Desc,
+	policy config.ErrorPolicy,
+	value float64,
+	failed bool,
+	labels []string,
+) {
+	var (
+		s  sample
+		ok bool
+	)
+
+	if failed {
+		s, ok = c.load(desc, labels)
+	} else {
+		s, ok = c.store(desc, labels, value), true
+	}
+
+	if !ok {
+		return
+	}
+
+	if !failed {
+		ch <- prometheus.MustNewConstMetric(
+			desc, prometheus.GaugeValue, s.value, withStatus(labels, successStatus)...,
+		)
+	} else if policy == config.ErrorPolicyLastSuccess {
+		ch <- prometheus.MustNewConstMetric(
+			desc, prometheus.GaugeValue, s.value, withStatus(labels, errorStatus)...,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		lastSuccessDesc, prometheus.GaugeValue, float64(s.timestamp.Unix()), labels...,
+	)
+}
+
+// collectStates sends samples of a series split by state label the same
+// way as collect. values holds value of each state and is ignored on
+// failure. The last success timestamp is exported once for all states.
+// labels must not contain the state and status labels, which are appended
+// here.
+func (c *SampleCache) collectStates(
+	ch chan<- prometheus.Metric,
+	desc, lastSuccessDesc *prometheus.Desc,
+	policy config.ErrorPolicy,
+	states []string,
+	values map[string]float64,
+	failed bool,
+	labels []string,
+) {
+	var lastSuccess *time.Time
+
+	for _, state := range states {
+		stateLabels := append(append([]string{}, labels...), state)
+
+		var (
+			s  sample
+			ok bool
+		)
+
+		if failed {
+			s, ok = c.load(desc, stateLabels)
+		} else {
+			s, ok = c.store(desc, stateLabels, values[state]), true
+		}
+
+		if !ok {
+			continue
+		}
+
+		lastSuccess = &s.timestamp
+
+		if !failed {
+			ch <- prometheus.MustNewConstMetric(
+				desc, prometheus.GaugeValue, s.value, withStatus(stateLabels, successStatus)...,
+			)
+		} else if policy == config.ErrorPolicyLastSuccess {
+			ch <- prometheus.MustNewConstMetric(
+				desc, prometheus.GaugeValue, s.value, withStatus(stateLabels, errorStatus)...,
+			)
+		}
+	}
+
+	if lastSuccess != nil {
+		ch <- prometheus.MustNewConstMetric(
+			lastSuccessDesc, prometheus.GaugeValue, float64(lastSuccess.Unix()), labels...,
+		)
+	}
+}
+
+func withStatus(labels []string, status string) []string {
+	return append(append([]string{}, labels...), status)
+}
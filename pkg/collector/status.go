@@ -0,0 +1,364 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+)
+
+type ChainStatus struct {
+	ChainName    string     `json:"chain_name"`
+	ChainID      string     `json:"chain_id"`
+	ClientID     string     `json:"client_id"`
+	ClientExpiry *time.Time `json:"client_expiry"`
+	// ClientHeight is the latest revision height of the client, zero if
+	// unknown.
+	ClientHeight uint64 `json:"client_height"`
+	// ClientLastUpdate is when the client was last updated, nil if unknown.
+	ClientLastUpdate *time.Time `json:"client_last_update"`
+	// ClientUpdateInterval is the time between the two most recent client
+	// updates, zero if unknown.
+	ClientUpdateInterval time.Duration `json:"-"`
+	// ClientExpiryThreshold is the configured expiry threshold of the client,
+	// zero if none.
+	ClientExpiryThreshold time.Duration `json:"-"`
+}
+
+type ChannelStatus struct {
+	SrcChannelID    string `json:"src_channel_id"`
+	DstChannelID    string `json:"dst_channel_id"`
+	SrcPortID       string `json:"src_port_id"`
+	DstPortID       string `json:"dst_port_id"`
+	Ordering        string `json:"ordering"`
+	SrcStuckPackets int    `json:"src_stuck_packets"`
+	DstStuckPackets int    `json:"dst_stuck_packets"`
+	// SrcSequences are sequences of stuck packets sent from source channel.
+	SrcSequences []uint64 `json:"src_sequences,omitempty"`
+	DstSequences []uint64 `json:"dst_sequences,omitempty"`
+	// SrcTimedOutPackets are stuck packets sent from source channel which
+	// already timed out on destination.
+	SrcTimedOutPackets int `json:"src_timed_out_packets"`
+	DstTimedOutPackets int `json:"dst_timed_out_packets"`
+	// SrcUnknownPackets are stuck packets sent from source channel with
+	// unknown timeout.
+	SrcUnknownPackets int `json:"src_unknown_packets"`
+	DstUnknownPackets int `json:"dst_unknown_packets"`
+	// SrcNextSequences are sequence counters of source channel, nil if
+	// unknown.
+	SrcNextSequences *ibc.Sequences `json:"src_next_sequences,omitempty"`
+	DstNextSequences *ibc.Sequences `json:"dst_next_sequences,omitempty"`
+}
+
+// Ordered returns whether c is an ordered channel, where a single stuck
+// packet blocks all the following ones.
+func (c ChannelStatus) Ordered() bool {
+	return c.Ordering == "ordered"
+}
+
+// PathStatus is the state of an IBC path as seen by the IBC collector. It
+// backs both exported metrics and the status API.
+type PathStatus struct {
+	Name      string            `json:"name"`
+	Chain1    ChainStatus       `json:"chain_1"`
+	Chain2    ChainStatus       `json:"chain_2"`
+	Channels  []ChannelStatus   `json:"channels"`
+	Operators []config.Operator `json:"operators"`
+	LastError string            `json:"last_error,omitempty"`
+	UpdatedAt *time.Time        `json:"updated_at"`
+
+	clientsErr  error
+	channelsErr error
+}
+
+// NewPathStatus returns status of a path that has not been collected yet.
+func NewPathStatus(path *config.IBCData, rpcs *map[string]config.RPC) PathStatus {
+	return PathStatus{
+		Name: path.Name(),
+		Chain1: ChainStatus{
+			ChainName: path.Chain1.ChainName,
+			ChainID:   (*rpcs)[path.Chain1.ChainName].ChainID,
+			ClientID:  path.Chain1.ClientID,
+
+			ClientExpiryThreshold: getClientExpiryThreshold(path, path.Chain1.ChainName, rpcs),
+		},
+		Chain2: ChainStatus{
+			ChainName: path.Chain2.ChainName,
+			ChainID:   (*rpcs)[path.Chain2.ChainName].ChainID,
+			ClientID:  path.Chain2.ClientID,
+
+			ClientExpiryThreshold: getClientExpiryThreshold(path, path.Chain2.ChainName, rpcs),
+		},
+		Channels:  []ChannelStatus{},
+		Operators: path.Operators,
+	}
+}
+
+// getClientExpiryThreshold returns expiry threshold of client hosted on chain.
+// Path threshold takes precedence over chain one.
+func getClientExpiryThreshold(path *config.IBCData, chainName string, rpcs *map[string]config.RPC) time.Duration {
+	if path.ClientExpiryThreshold != 0 {
+		return path.ClientExpiryThreshold
+	}
+
+	return (*rpcs)[chainName].ClientExpiryThreshold
+}
+
+// GetPathStatus queries clients and channels of an IBC path. Metadata of
+// stuck packets is kept in packets, which may be nil.
+func GetPathStatus(
+	ctx context.Context, path *config.IBCData, rpcs *map[string]config.RPC, packets *PacketCache,
+) PathStatus {
+	status := NewPathStatus(path, rpcs)
+	now := time.Now()
+	status.UpdatedAt = &now
+
+	ci, err := ibc.GetClientsInfo(ctx, path, rpcs)
+	if err != nil {
+		status.clientsErr = err
+	} else {
+		status.Chain1.ClientExpiry = &ci.ChainAClientExpiration
+		status.Chain2.ClientExpiry = &ci.ChainBClientExpiration
+
+		if ci.ChainAClientInfo.LatestHeight != nil {
+			status.Chain1.ClientHeight = ci.ChainAClientInfo.LatestHeight.GetRevisionHeight()
+		}
+
+		if ci.ChainBClientInfo.LatestHeight != nil {
+			status.Chain2.ClientHeight = ci.ChainBClientInfo.LatestHeight.GetRevisionHeight()
+		}
+
+		status.Chain1.setClientUpdate(ci.ChainAClientUpdate)
+		status.Chain2.setClientUpdate(ci.ChainBClientUpdate)
+	}
+
+	var lookup ibc.PacketLookup
+	if packets != nil {
+		lookup = packets.Lookup
+	}
+
+	channels, err := ibc.GetChannelsInfo(ctx, path, rpcs, lookup)
+	if err != nil {
+		status.channelsErr = err
+	}
+
+	for _, c := range channels.Channels {
+		status.Channels = append(status.Channels, ChannelStatus{
+			SrcChannelID:    c.Source,
+			DstChannelID:    c.Destination,
+			SrcPortID:       c.SourcePort,
+			DstPortID:       c.DestinationPort,
+			Ordering:        c.Ordering,
+			SrcStuckPackets: c.StuckPackets.Source,
+			DstStuckPackets: c.StuckPackets.Destination,
+			SrcSequences:    c.Sequences.Source,
+			DstSequences:    c.Sequences.Destination,
+
+			SrcTimedOutPackets: c.TimedOutPackets.Source,
+			DstTimedOutPackets: c.TimedOutPackets.Destination,
+			SrcUnknownPackets:  c.UnknownPackets.Source,
+			DstUnknownPackets:  c.UnknownPackets.Destination,
+			SrcNextSequences:   c.NextSequences.Source,
+			DstNextSequences:   c.NextSequences.Destination,
+		})
+	}
+
+	if err := errors.Join(status.clientsErr, status.channelsErr); err != nil {
+		status.LastError = err.Error()
+	}
+
+	return status
+}
+
+// setClientUpdate sets last update of the client from block time of the most
+// recent update, falling back to timestamp of its latest consensus state.
+func (c *ChainStatus) setClientUpdate(update ibc.ClientUpdate) {
+	switch {
+	case !update.LastUpdate.IsZero():
+		c.ClientLastUpdate = &update.LastUpdate
+	case !update.ConsensusTimestamp.IsZero():
+		c.ClientLastUpdate = &update.ConsensusTimestamp
+	}
+
+	c.ClientUpdateInterval = update.Interval
+}
+
+// MinClientExpiry returns the earliest expiry of path clients or nil if
+// none is known.
+func (p PathStatus) MinClientExpiry() *time.Time {
+	switch {
+	case p.Chain1.ClientExpiry == nil:
+		return p.Chain2.ClientExpiry
+	case p.Chain2.ClientExpiry == nil:
+		return p.Chain1.ClientExpiry
+	case p.Chain2.ClientExpiry.Before(*p.Chain1.ClientExpiry):
+		return p.Chain2.ClientExpiry
+	default:
+		return p.Chain1.ClientExpiry
+	}
+}
+
+// StuckPackets returns total count of stuck packets on path channels.
+func (p PathStatus) StuckPackets() int {
+	total := 0
+
+	for _, c := range p.Channels {
+		total += c.SrcStuckPackets + c.DstStuckPackets
+	}
+
+	return total
+}
+
+// AccountStatus is the state of a configured account as seen by the wallet
+// balance collector.
+type AccountStatus struct {
+	Address   string    `json:"address"`
+	ChainName string    `json:"chain_name"`
+	ChainID   string    `json:"chain_id"`
+	Denom     string    `json:"denom"`
+	Tags      []string  `json:"tags"`
+	Balance   *math.Int `json:"balance"`
+	// MinBalance is the configured minimum balance of the account, empty
+	// if not set.
+	MinBalance string     `json:"min_balance,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// Key identifies account balance series.
+func (a AccountStatus) Key() string {
+	return a.Address + "/" + a.ChainName + "/" + a.Denom
+}
+
+// StatusStore keeps the latest status of each collected path and account.
+// Values of failed queries are kept from the previous successful collection.
+type StatusStore struct {
+	mu       sync.RWMutex
+	paths    map[string]PathStatus
+	accounts map[string]AccountStatus
+}
+
+func NewStatusStore() *StatusStore {
+	return &StatusStore{
+		paths:    map[string]PathStatus{},
+		accounts: map[string]AccountStatus{},
+	}
+}
+
+func (s *StatusStore) UpdatePath(status PathStatus) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.paths[status.Name]; ok {
+		if status.clientsErr != nil {
+			status.Chain1.ClientExpiry = prev.Chain1.ClientExpiry
+			status.Chain2.ClientExpiry = prev.Chain2.ClientExpiry
+			status.Chain1.ClientHeight = prev.Chain1.ClientHeight
+			status.Chain2.ClientHeight = prev.Chain2.ClientHeight
+			status.Chain1.ClientLastUpdate = prev.Chain1.ClientLastUpdate
+			status.Chain2.ClientLastUpdate = prev.Chain2.ClientLastUpdate
+			status.Chain1.ClientUpdateInterval = prev.Chain1.ClientUpdateInterval
+			status.Chain2.ClientUpdateInterval = prev.Chain2.ClientUpdateInterval
+		}
+
+		if status.channelsErr != nil {
+			status.Channels = prev.Channels
+		}
+	}
+
+	s.paths[status.Name] = status
+}
+
+// Path returns the latest status of path with name.
+// RetainPaths removes status of paths not in names, e.g. removed from the
+// registry, so they are no longer evaluated.
+func (s *StatusStore) RetainPaths(names []string) {
+	if s == nil {
+		return
+	}
+
+	retained := make(map[string]bool, len(names))
+	for _, name := range names {
+		retained[name] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.paths {
+		if !retained[name] {
+			delete(s.paths, name)
+		}
+	}
+}
+
+func (s *StatusStore) Path(name string) (PathStatus, bool) {
+	if s == nil {
+		return PathStatus{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status, ok := s.paths[name]
+
+	return status, ok
+}
+
+// Paths returns the latest status of all collected paths.
+func (s *StatusStore) Paths() []PathStatus {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paths := make([]PathStatus, 0, len(s.paths))
+	for _, p := range s.paths {
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+func (s *StatusStore) UpdateAccount(status AccountStatus) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.accounts[status.Key()]; ok && status.Balance == nil {
+		status.Balance = prev.Balance
+	}
+
+	s.accounts[status.Key()] = status
+}
+
+// Accounts returns the latest status of all collected accounts.
+func (s *StatusStore) Accounts() []AccountStatus {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]AccountStatus, 0, len(s.accounts))
+	for _, a := range s.accounts {
+		accounts = append(accounts, a)
+	}
+
+	return accounts
+}
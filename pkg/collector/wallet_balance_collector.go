@@ -3,8 +3,10 @@ package collector
 import (
 	"context"
 	"math/big"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -15,27 +17,76 @@ import (
 )
 
 const (
-	walletBalanceMetricName = "cosmos_wallet_balance"
+	walletBalanceMetricName             = "cosmos_wallet_balance"
+	walletBalanceLastSuccessMetricName  = "cosmos_wallet_balance_last_success_timestamp"
+	walletBalanceMinThresholdMetricName = "cosmos_wallet_balance_min_threshold"
+	walletBalanceBurnRateMetricName     = "cosmos_wallet_balance_burn_rate"
+	walletBalanceRunwayMetricName       = "cosmos_wallet_balance_runway_seconds"
 )
 
-var walletBalance = prometheus.NewDesc(
-	walletBalanceMetricName,
-	"Returns wallet balance for an address on a chain.",
-	[]string{"account", "chain_id", "denom", "status", "tags"}, nil,
+var (
+	walletBalance = prometheus.NewDesc(
+		walletBalanceMetricName,
+		"Returns wallet balance for an address on a chain.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "tags", "status"}, nil,
+	)
+	walletBalanceLastSuccess = prometheus.NewDesc(
+		walletBalanceLastSuccessMetricName,
+		"Returns unixtime of the last successful wallet balance query.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "tags"}, nil,
+	)
+	walletBalanceMinThreshold = prometheus.NewDesc(
+		walletBalanceMinThresholdMetricName,
+		"Returns configured minimum wallet balance for an address on a chain.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "tags"}, nil,
+	)
+	walletBalanceBurnRate = prometheus.NewDesc(
+		walletBalanceBurnRateMetricName,
+		"Returns wallet spend rate per second excluding top-ups.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "tags"}, nil,
+	)
+	walletBalanceRunway = prometheus.NewDesc(
+		walletBalanceRunwayMetricName,
+		"Returns estimated seconds until wallet balance drops to the minimum threshold.",
+		[]string{"account", "chain_id", "denom", "base_denom", "trace_path", "tags"}, nil,
+	)
 )
 
+// balanceQuery sets balance of account a queried from its chain in rpcs.
+type balanceQuery func(ctx context.Context, a *config.Account, rpcs *map[string]config.RPC) error
+
 type WalletBalanceCollector struct {
-	RPCs     *map[string]config.RPC
-	Accounts []*config.Account
+	RPCs          *map[string]config.RPC
+	Accounts      []*config.Account
+	ErrorPolicies config.ErrorPolicies
+	Cache         *SampleCache
+	Status        *StatusStore
+	History       *BalanceHistory
+	Denoms        *DenomCache
+	// Ctx bounds queries of a collection, e.g. of a probe, if not nil.
+	Ctx context.Context
+
+	query balanceQuery
 }
 
 func (wb WalletBalanceCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- walletBalance
+	ch <- walletBalanceLastSuccess
+	ch <- walletBalanceMinThreshold
+	ch <- walletBalanceBurnRate
+	ch <- walletBalanceRunway
 }
 
 func (wb WalletBalanceCollector) Collect(ch chan<- prometheus.Metric) {
 	log.Debug("Start collecting", zap.String("metric", walletBalanceMetricName))
 
+	ctx := collectContext(wb.Ctx)
+
+	query := wb.query
+	if query == nil {
+		query = getBalance
+	}
+
 	var wg sync.WaitGroup
 
 	for _, a := range wb.Accounts {
@@ -45,32 +96,104 @@ func (wb WalletBalanceCollector) Collect(ch chan<- prometheus.Metric) {
 			defer wg.Done()
 
 			balance := 0.0
-			status := successStatus
+			now := time.Now()
+			status := AccountStatus{
+				Address:    account.Address,
+				ChainName:  account.ChainName,
+				ChainID:    (*wb.RPCs)[account.ChainName].ChainID,
+				Denom:      account.Denom,
+				Tags:       account.Tags,
+				MinBalance: account.MinBalance,
+				UpdatedAt:  &now,
+			}
 
-			err := getBalance(ctx, &account, wb.RPCs)
+			err := query(ctx, &account, wb.RPCs)
 			if err != nil {
-				status = errorStatus
+				status.LastError = err.Error()
 
 				log.Error(err.Error(), zap.Any("account", account))
 			} else {
+				status.Balance = &account.Balance
 				// Convert to a big float to get a float64 for metrics
 				balance, _ = big.NewFloat(0.0).SetInt(account.Balance.BigInt()).Float64()
 			}
 
-			ch <- prometheus.MustNewConstMetric(
+			wb.Status.UpdateAccount(status)
+
+			if status.Balance != nil {
+				wb.History.Add(status.Key(), now, balance)
+			}
+
+			trace, err := wb.Denoms.Trace(ctx, (*wb.RPCs)[account.ChainName], account.Denom)
+			if err != nil {
+				log.Error(err.Error(), zap.Any("account", account))
+			}
+
+			labels := []string{
+				account.Address,
+				(*wb.RPCs)[account.ChainName].ChainID,
+				account.Denom,
+				trace.BaseDenom,
+				trace.TracePath,
+				strings.Join(account.Tags, ","),
+			}
+
+			wb.Cache.collect(
+				ch,
 				walletBalance,
-				prometheus.GaugeValue,
+				walletBalanceLastSuccess,
+				wb.ErrorPolicies.WalletBalance,
 				balance,
-				[]string{account.Address, (*wb.RPCs)[account.ChainName].ChainID, account.Denom, status, strings.Join(account.Tags, ",")}...,
+				status.Balance == nil,
+				labels,
 			)
+
+			minBalance := 0.0
+
+			if account.MinBalance != "" {
+				minBalance, err = strconv.ParseFloat(account.MinBalance, 64)
+				if err != nil {
+					log.Error(err.Error(), zap.Any("account", account))
+					return
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					walletBalanceMinThreshold, prometheus.GaugeValue, minBalance, labels...,
+				)
+			}
+
+			if status.Balance != nil {
+				wb.collectRunway(ch, status.Key(), balance, minBalance, labels)
+			}
 		}(*a)
 	}
 
 	wg.Wait()
 
+	if err := wb.History.Save(); err != nil {
+		log.Error("Failed to save balance history", zap.Error(err))
+	}
+
 	log.Debug("Stop collecting", zap.String("metric", walletBalanceMetricName))
 }
 
+// collectRunway exports spend rate and runway of account once enough
+// balance history is collected. Runway is not exported while nothing is spent.
+func (wb WalletBalanceCollector) collectRunway(
+	ch chan<- prometheus.Metric, key string, balance, minBalance float64, labels []string,
+) {
+	rate, ok := wb.History.BurnRate(key)
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(walletBalanceBurnRate, prometheus.GaugeValue, rate, labels...)
+
+	if runway, ok := Runway(balance, minBalance, rate); ok {
+		ch <- prometheus.MustNewConstMetric(walletBalanceRunway, prometheus.GaugeValue, runway, labels...)
+	}
+}
+
 func getBalance(ctx context.Context, a *config.Account, rpcs *map[string]config.RPC) error {
 	chain, err := chain.PrepChain(ctx, chain.Info{
 		ChainID: (*rpcs)[a.ChainName].ChainID,
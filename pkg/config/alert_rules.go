@@ -0,0 +1,56 @@
+package config
+
+import "time"
+
+const defaultAlertRulesStaleAfter = time.Hour
+
+// AlertRules configures thresholds of generated Prometheus alerting rules.
+type AlertRules struct {
+	ClientExpiry  *ClientExpiryAlert   `yaml:"clientExpiry"`
+	StuckPackets  *StuckPacketsAlert   `yaml:"stuckPackets"`
+	WalletBalance []WalletBalanceAlert `yaml:"walletBalance" validate:"dive"`
+	// StaleAfter is how long a series may go without successful collection
+	// before a stale alert fires.
+	StaleAfter time.Duration `yaml:"staleAfter" validate:"gte=0"`
+	// Labels are added to every generated alert, e.g. severity.
+	Labels map[string]string `yaml:"labels"`
+}
+
+// ClientExpiryAlert fires when a light client expires within Within. Chains
+// overrides Within for clients hosted on given chain names.
+type ClientExpiryAlert struct {
+	Within time.Duration            `yaml:"within" validate:"required,gt=0"`
+	For    time.Duration            `yaml:"for" validate:"gte=0"`
+	Chains map[string]time.Duration `yaml:"chains" validate:"dive,gt=0"`
+}
+
+// StuckPacketsAlert fires when a channel has more stuck packets than Count.
+// Chains overrides Count for channels on given source chain names.
+type StuckPacketsAlert struct {
+	Count  int            `yaml:"count" validate:"gte=0"`
+	For    time.Duration  `yaml:"for" validate:"gte=0"`
+	Chains map[string]int `yaml:"chains" validate:"dive,gte=0"`
+}
+
+// WalletBalanceAlert fires when balance of an account in Denom is below Min.
+// Tags overrides Min for accounts with given tag, the first matching tag wins.
+type WalletBalanceAlert struct {
+	Denom string          `yaml:"denom" validate:"required"`
+	Min   string          `yaml:"min" validate:"required,numeric"`
+	For   time.Duration   `yaml:"for" validate:"gte=0"`
+	Tags  []TagMinBalance `yaml:"tags" validate:"dive"`
+}
+
+type TagMinBalance struct {
+	Tag string `yaml:"tag" validate:"required"`
+	Min string `yaml:"min" validate:"required,numeric"`
+}
+
+// GetStaleAfter returns StaleAfter or its default.
+func (r *AlertRules) GetStaleAfter() time.Duration {
+	if r.StaleAfter == 0 {
+		return defaultAlertRulesStaleAfter
+	}
+
+	return r.StaleAfter
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"cosmossdk.io/math"
 	"github.com/caarlos0/env/v9"
@@ -20,7 +22,12 @@ import (
 	log "github.com/archway-network/relayer_exporter/pkg/logger"
 )
 
-const ibcPathSuffix = ".json"
+const (
+	ibcPathSuffix = ".json"
+
+	defaultBalanceHistoryWindow = 24 * time.Hour
+	defaultFeesMaxPages         = 10
+)
 
 var (
 	ErrGitHubClient        = errors.New("GitHub client not provided")
@@ -33,6 +40,8 @@ type Account struct {
 	ChainName string `yaml:"chainName" validate:"required"`
 	Balance   math.Int
 	Tags      []string `yaml:"tags,omitempty"`
+	// MinBalance is exported as balance threshold of the account.
+	MinBalance string `yaml:"minBalance,omitempty" validate:"omitempty,numeric"`
 }
 
 type RPC struct {
@@ -40,6 +49,71 @@ type RPC struct {
 	ChainID   string `yaml:"chainId" validate:"required"`
 	URL       string `yaml:"url" validate:"required,http_url,has_port"`
 	Timeout   string `yaml:"timeout"`
+	// ClientExpiryThreshold is exported as expiry threshold of clients
+	// hosted on the chain.
+	ClientExpiryThreshold time.Duration `yaml:"clientExpiryThreshold" validate:"gte=0"`
+	// Key in keyring signing transactions on the chain, needed only by
+	// remediation.
+	Key           string  `yaml:"key"`
+	AccountPrefix string  `yaml:"accountPrefix"`
+	GasPrices     string  `yaml:"gasPrices"`
+	GasAdjustment float64 `yaml:"gasAdjustment" validate:"gte=0"`
+}
+
+// BalanceHistory configures wallet balance history used to estimate spend
+// rate and runway of accounts.
+type BalanceHistory struct {
+	// Window of samples spend rate is computed from.
+	Window time.Duration `yaml:"window" validate:"gte=0"`
+}
+
+// Store configures embedded store persisting state across restarts.
+type Store struct {
+	Path string `yaml:"path" validate:"required"`
+}
+
+// Fees enables accounting of fees spent by transactions of configured
+// accounts.
+type Fees struct {
+	// MaxPages limits transaction search pages per account per collection.
+	MaxPages int `yaml:"maxPages" validate:"gte=0"`
+}
+
+// GetMaxPages returns MaxPages or its default.
+func (f *Fees) GetMaxPages() int {
+	if f.MaxPages == 0 {
+		return defaultFeesMaxPages
+	}
+
+	return f.MaxPages
+}
+
+// Escrow enables monitoring of ICS-20 escrow accounts of transfer channels.
+type Escrow struct {
+	// Paths limits monitored paths by name, all paths are monitored if empty.
+	Paths []string `yaml:"paths"`
+}
+
+// Includes reports if escrows of path are monitored.
+func (e *Escrow) Includes(path *IBCData) bool {
+	if len(e.Paths) == 0 {
+		return true
+	}
+
+	for _, name := range e.Paths {
+		if path.HasName(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PathThreshold overrides chain thresholds for clients of an IBC path.
+type PathThreshold struct {
+	// Path name in <chain1>-<chain2> format, chains can be given in any order.
+	Path                  string        `yaml:"path" validate:"required"`
+	ClientExpiryThreshold time.Duration `yaml:"clientExpiryThreshold" validate:"gt=0"`
 }
 
 type GitHub struct {
@@ -50,11 +124,40 @@ type GitHub struct {
 	Token          string `env:"GITHUB_TOKEN"`
 }
 
+// ErrorPolicy defines what a collector exports for a series when the
+// upstream query fails.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyOmit drops the sample for the failed series.
+	ErrorPolicyOmit ErrorPolicy = "omit"
+	// ErrorPolicyLastSuccess exports the last successfully collected value.
+	ErrorPolicyLastSuccess ErrorPolicy = "lastSuccess"
+)
+
+// ErrorPolicies holds the error policy for each metric family.
+type ErrorPolicies struct {
+	ClientExpiry  ErrorPolicy `yaml:"clientExpiry" validate:"omitempty,oneof=omit lastSuccess"`
+	StuckPackets  ErrorPolicy `yaml:"stuckPackets" validate:"omitempty,oneof=omit lastSuccess"`
+	WalletBalance ErrorPolicy `yaml:"walletBalance" validate:"omitempty,oneof=omit lastSuccess"`
+}
+
 type Config struct {
-	Accounts         []*Account `yaml:"accounts"`
-	GlobalRPCTimeout string     `env:"GLOBAL_RPC_TIMEOUT" envDefault:"5s"`
-	RPCs             []*RPC     `yaml:"rpc"`
-	GitHub           *GitHub    `yaml:"github"`
+	Accounts         []*Account       `yaml:"accounts"`
+	GlobalRPCTimeout string           `env:"GLOBAL_RPC_TIMEOUT" envDefault:"5s"`
+	RPCs             []*RPC           `yaml:"rpc"`
+	GitHub           *GitHub          `yaml:"github"`
+	ErrorPolicies    *ErrorPolicies   `yaml:"errorPolicies"`
+	Notifications    *Notifications   `yaml:"notifications"`
+	AlertRules       *AlertRules      `yaml:"alertRules"`
+	PathThresholds   []*PathThreshold `yaml:"pathThresholds" validate:"dive"`
+	BalanceHistory   *BalanceHistory  `yaml:"balanceHistory"`
+	Fees             *Fees            `yaml:"fees"`
+	Escrow           *Escrow          `yaml:"escrow"`
+	ForwardRoutes    []*ForwardRoute  `yaml:"forwardRoutes" validate:"dive"`
+	Store            *Store           `yaml:"store"`
+	Keyring          *Keyring         `yaml:"keyring"`
+	Remediation      *Remediation     `yaml:"remediation"`
 }
 
 type IBCChainMeta struct {
@@ -100,6 +203,8 @@ type IBCData struct {
 	Chain2    IBCChainMeta `json:"chain_2"`
 	Channels  []Channel    `json:"channels"`
 	Operators []Operator   `json:"operators"`
+	// ClientExpiryThreshold is set from config path thresholds.
+	ClientExpiryThreshold time.Duration `json:"-"`
 }
 
 type Discord struct {
@@ -107,6 +212,17 @@ type Discord struct {
 	ID     string `json:"id"`
 }
 
+// Name returns name of IBC path in <chain1>-<chain2> format as used by
+// IBC registry file names.
+func (i *IBCData) Name() string {
+	return i.Chain1.ChainName + "-" + i.Chain2.ChainName
+}
+
+// HasName reports if name is the path name with chains in any order.
+func (i *IBCData) HasName(name string) bool {
+	return name == i.Name() || name == i.Chain2.ChainName+"-"+i.Chain1.ChainName
+}
+
 // GetRPCsMap uses the provided config file to return a map of chain
 // chain_names to RPCs. It uses IBCData already extracted from
 // github IBC registry to validate config for missing RPCs and raises
@@ -125,6 +241,34 @@ func (c *Config) GetRPCsMap() *map[string]RPC {
 	return &rpcs
 }
 
+// GetErrorPolicies returns configured error policies with unset
+// metric families defaulting to ErrorPolicyOmit.
+func (c *Config) GetErrorPolicies() ErrorPolicies {
+	policies := ErrorPolicies{
+		ClientExpiry:  ErrorPolicyOmit,
+		StuckPackets:  ErrorPolicyOmit,
+		WalletBalance: ErrorPolicyOmit,
+	}
+
+	if c.ErrorPolicies == nil {
+		return policies
+	}
+
+	if c.ErrorPolicies.ClientExpiry != "" {
+		policies.ClientExpiry = c.ErrorPolicies.ClientExpiry
+	}
+
+	if c.ErrorPolicies.StuckPackets != "" {
+		policies.StuckPackets = c.ErrorPolicies.StuckPackets
+	}
+
+	if c.ErrorPolicies.WalletBalance != "" {
+		policies.WalletBalance = c.ErrorPolicies.WalletBalance
+	}
+
+	return policies
+}
+
 func (c *Config) IBCPaths(ctx context.Context) ([]*IBCData, error) {
 	client := github.NewClient(nil)
 
@@ -162,10 +306,22 @@ func (c *Config) IBCPaths(ctx context.Context) ([]*IBCData, error) {
 	}
 
 	paths = append(paths, testnetsPaths...)
+	c.SetPathThresholds(paths)
 
 	return paths, nil
 }
 
+// SetPathThresholds sets thresholds configured for paths.
+func (c *Config) SetPathThresholds(paths []*IBCData) {
+	for _, p := range paths {
+		for _, t := range c.PathThresholds {
+			if p.HasName(t.Path) {
+				p.ClientExpiryThreshold = t.ClientExpiryThreshold
+			}
+		}
+	}
+}
+
 func (c *Config) getPaths(ctx context.Context, dir string, client *github.Client) ([]*IBCData, error) {
 	if client == nil {
 		return nil, ErrGitHubClient
@@ -211,7 +367,44 @@ func (c *Config) getPaths(ctx context.Context, dir string, client *github.Client
 	return ibcs, nil
 }
 
+// LocalIBCPaths reads IBC paths from registry JSON files in a local
+// directory, e.g. a checkout of the networks repo.
+func LocalIBCPaths(dir string) ([]*IBCData, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ibcs := []*IBCData{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ibcPathSuffix) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		ibc := &IBCData{}
+		if err := json.Unmarshal(content, ibc); err != nil {
+			return nil, fmt.Errorf("%w in %s", err, entry.Name())
+		}
+
+		ibcs = append(ibcs, ibc)
+	}
+
+	return ibcs, nil
+}
+
 func (c *Config) Validate() error {
+	return errors.Join(c.ValidationErrors()...)
+}
+
+// ValidationErrors validates the whole config and returns every problem
+// found instead of stopping at the first one.
+func (c *Config) ValidationErrors() []error {
 	validate := validator.New(validator.WithRequiredStructEnabled())
 
 	// register custom validation for http url as expected by go relayer i.e.
@@ -237,40 +430,52 @@ func (c *Config) Validate() error {
 		return true
 	})
 	if err != nil {
-		return err
+		return []error{err}
 	}
 
+	errs := []error{}
+
 	// validate top level fields
 	if err := validate.Struct(c); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
 	// validate RPCs
 	for _, rpc := range c.RPCs {
 		if err := validate.Struct(rpc); err != nil {
-			return fmt.Errorf("%v for RPC config: %+v", err, rpc)
+			errs = append(errs, fmt.Errorf("%v for RPC config: %+v", err, rpc))
 		}
 	}
 
+	if c.Notifications != nil {
+		errs = append(errs, c.Notifications.validationErrors()...)
+	}
+
+	if c.Remediation != nil {
+		errs = append(errs, c.Remediation.validationErrors(c.RPCs)...)
+	}
+
+	errs = append(errs, forwardRoutesValidationErrors(c.ForwardRoutes, c.RPCs)...)
+
 	// validate accounts
+	rpcMap := c.GetRPCsMap()
+
 	for _, account := range c.Accounts {
 		if err := validate.Struct(account); err != nil {
-			return fmt.Errorf("%v for accounts config: %+v", err, account)
+			errs = append(errs, fmt.Errorf("%v for accounts config: %+v", err, account))
 		}
 
-		rpcMap := c.GetRPCsMap()
-		if rpcMap != nil {
-			_, ok := (*rpcMap)[account.ChainName]
-			if !ok {
-				return fmt.Errorf(ErrMissingRPCConfigMsg, account.ChainName)
-			}
+		if _, ok := (*rpcMap)[account.ChainName]; account.ChainName != "" && !ok {
+			errs = append(errs, fmt.Errorf(ErrMissingRPCConfigMsg, account.ChainName))
 		}
 	}
 
-	return nil
+	return errs
 }
 
-func NewConfig(configPath string) (*Config, error) {
+// LoadConfig reads config file and environment variables without
+// validating the result.
+func LoadConfig(configPath string) (*Config, error) {
 	config := &Config{}
 
 	file, err := os.Open(configPath)
@@ -279,7 +484,11 @@ func NewConfig(configPath string) (*Config, error) {
 	}
 	defer file.Close()
 
+	// Unknown keys are rejected, so typos and removed options don't silently
+	// fall back to defaults.
 	d := yaml.NewDecoder(file)
+	d.KnownFields(true)
+
 	if err := d.Decode(config); err != nil {
 		return nil, err
 	}
@@ -288,6 +497,15 @@ func NewConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	return config, nil
+}
+
+func NewConfig(configPath string) (*Config, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
 	err = config.Validate()
 	if err != nil {
 		return nil, err
@@ -295,3 +513,19 @@ func NewConfig(configPath string) (*Config, error) {
 
 	return config, nil
 }
+
+// GetBalanceHistory returns balance history config with unset fields
+// defaulted.
+func (c *Config) GetBalanceHistory() BalanceHistory {
+	history := BalanceHistory{Window: defaultBalanceHistoryWindow}
+
+	if c.BalanceHistory == nil {
+		return history
+	}
+
+	if c.BalanceHistory.Window != 0 {
+		history.Window = c.BalanceHistory.Window
+	}
+
+	return history
+}
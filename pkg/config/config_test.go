@@ -3,7 +3,9 @@ package config
 import (
 	"context"
 	"errors"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -122,3 +124,279 @@ func TestGetPaths(t *testing.T) {
 		t.Errorf("Expected error %q, got %q", expError, err)
 	}
 }
+
+func TestGetErrorPolicies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policies *ErrorPolicies
+		expected ErrorPolicies
+	}{
+		{
+			name:     "Defaults",
+			policies: nil,
+			expected: ErrorPolicies{
+				ClientExpiry:  ErrorPolicyOmit,
+				StuckPackets:  ErrorPolicyOmit,
+				WalletBalance: ErrorPolicyOmit,
+			},
+		},
+		{
+			name:     "Partial Override",
+			policies: &ErrorPolicies{ClientExpiry: ErrorPolicyLastSuccess},
+			expected: ErrorPolicies{
+				ClientExpiry:  ErrorPolicyLastSuccess,
+				StuckPackets:  ErrorPolicyOmit,
+				WalletBalance: ErrorPolicyOmit,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Config{ErrorPolicies: tc.policies}
+			assert.Equal(t, tc.expected, cfg.GetErrorPolicies())
+		})
+	}
+}
+
+func TestValidateErrorPolicies(t *testing.T) {
+	cfg := Config{ErrorPolicies: &ErrorPolicies{WalletBalance: "zero"}}
+	assert.Error(t, cfg.Validate())
+
+	cfg = Config{ErrorPolicies: &ErrorPolicies{WalletBalance: ErrorPolicyLastSuccess}}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLocalIBCPaths(t *testing.T) {
+	paths, err := LocalIBCPaths("testdata/_IBC")
+	assert.NoError(t, err)
+	assert.Len(t, paths, 1)
+	assert.Equal(t, "archway-osmosis", paths[0].Name())
+	assert.Len(t, paths[0].Channels, 3)
+
+	_, err = LocalIBCPaths("testdata/missing")
+	assert.Error(t, err)
+}
+
+func TestValidationErrors(t *testing.T) {
+	cfg := Config{
+		RPCs: []*RPC{
+			{ChainName: "archway", ChainID: "archway-1", URL: "https://rpc.mainnet.archway.io"},
+			{ChainName: "osmosis", URL: "https://rpc.osmosis.zone:443"},
+		},
+		Accounts: []*Account{
+			{Address: "archway1abc", ChainName: "noble"},
+		},
+	}
+
+	errs := cfg.ValidationErrors()
+	assert.Len(t, errs, 4)
+	assert.EqualError(t, errs[3], "missing RPC config for chain: noble")
+	assert.Error(t, cfg.Validate())
+}
+
+func TestLintIBCPaths(t *testing.T) {
+	rpcs := &map[string]RPC{
+		"archway": {ChainName: "archway"},
+		"osmosis": {ChainName: "osmosis"},
+	}
+
+	// Wildcard ICA channels repeat on the host chain.
+	errs := LintIBCPaths("testdata/_IBC", rpcs)
+	assert.Empty(t, errs)
+
+	errs = LintIBCPaths("testdata/invalid_IBC", rpcs)
+
+	msgs := []string{}
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		"testdata/invalid_IBC/archway-noble.json: missing RPC config for chain: noble",
+		"testdata/invalid_IBC/osmosis-archway.json: schema: /chain_1/connection_id: " +
+			"does not match pattern '^connection-[0-9]+$'",
+		"testdata/invalid_IBC/osmosis-archway.json: duplicate channel transfer/channel-5 on osmosis",
+		`testdata/invalid_IBC/osmosis-archway.json: malformed discord id "archway#1234" for operator Archway`,
+		"testdata/invalid_IBC/osmosis-archway.json: duplicate path osmosis-archway, " +
+			"already defined in testdata/invalid_IBC/archway-osmosis.json",
+	}, msgs)
+}
+
+func TestNotificationsConfig(t *testing.T) {
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.com/api/webhooks/1/token")
+
+	file, err := os.CreateTemp(t.TempDir(), "config.yaml")
+	assert.NoError(t, err)
+
+	_, err = file.WriteString(`
+notifications:
+  interval: 1m
+  discord: {}
+  rules:
+    clientExpiry:
+      within: 72h
+    stuckPackets:
+      count: 5
+      for: 30m
+    walletBalance:
+      - denom: aarch
+        min: "1000000000000000000"
+`)
+	assert.NoError(t, err)
+
+	cfg, err := NewConfig(file.Name())
+	assert.NoError(t, err)
+
+	n := cfg.Notifications
+	assert.Equal(t, "https://discord.com/api/webhooks/1/token", n.Discord.WebhookURL)
+	assert.Equal(t, time.Minute, n.GetInterval())
+	assert.Equal(t, 4*time.Hour, n.GetRepeatInterval())
+	assert.Equal(t, 72*time.Hour, n.Rules.ClientExpiry.Within)
+	assert.Equal(t, &StuckPacketsRule{Count: 5, For: 30 * time.Minute}, n.Rules.StuckPackets)
+
+	n.Rules.WalletBalance[0].Min = "1 ARCH"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestLoadConfigUnknownKey(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "config.yaml")
+	assert.NoError(t, err)
+
+	_, err = file.WriteString(`
+balanceHistory:
+  window: 12h
+  file: /data/balance_history.json
+`)
+	assert.NoError(t, err)
+
+	_, err = LoadConfig(file.Name())
+	assert.ErrorContains(t, err, "field file not found in type config.BalanceHistory")
+}
+
+func TestNotificationsValidationErrors(t *testing.T) {
+	n := &Notifications{
+		Discord: &DiscordWebhook{WebhookURL: "https://discord.com/api/webhooks/1/token"},
+		Receivers: []*Receiver{
+			{Name: "slack", Slack: &SlackWebhook{WebhookURL: "https://hooks.slack.com/services/1"}},
+			{Name: "slack", Webhook: &Webhook{URL: "https://example.com"}},
+			{Name: "discord", Discord: &DiscordWebhook{WebhookURL: "https://discord.com/api/webhooks/2/token"}},
+			{Name: "empty"},
+		},
+		Routes: []*Route{
+			{Receiver: "slack", Match: RouteMatch{Tags: []string{"feegrant"}}},
+			{Receiver: "pagerduty", Match: RouteMatch{Chains: []string{"archway"}}},
+		},
+	}
+
+	msgs := []string{}
+	for _, err := range n.validationErrors() {
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`duplicate receiver "slack"`,
+		`receiver name "discord" is reserved for top level discord config`,
+		`receiver "empty" must have exactly one backend, got 0`,
+		`route to unknown receiver "pagerduty"`,
+	}, msgs)
+}
+
+func TestAlertRulesValidation(t *testing.T) {
+	cfg := Config{
+		AlertRules: &AlertRules{
+			ClientExpiry: &ClientExpiryAlert{Within: 72 * time.Hour, Chains: map[string]time.Duration{"osmosis": 0}},
+			WalletBalance: []WalletBalanceAlert{
+				{Denom: "aarch", Min: "1000", Tags: []TagMinBalance{{Tag: "feegrant", Min: "1 ARCH"}}},
+			},
+		},
+	}
+
+	errs := cfg.ValidationErrors()
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "AlertRules.ClientExpiry.Chains[osmosis]")
+	assert.ErrorContains(t, errs[0], "AlertRules.WalletBalance[0].Tags[0].Min")
+
+	assert.Equal(t, time.Hour, cfg.AlertRules.GetStaleAfter())
+}
+
+func TestSetPathThresholds(t *testing.T) {
+	cfg := Config{
+		PathThresholds: []*PathThreshold{
+			{Path: "osmosis-archway", ClientExpiryThreshold: 168 * time.Hour},
+		},
+	}
+
+	paths := []*IBCData{
+		{Chain1: IBCChainMeta{ChainName: "archway"}, Chain2: IBCChainMeta{ChainName: "osmosis"}},
+		{Chain1: IBCChainMeta{ChainName: "archway"}, Chain2: IBCChainMeta{ChainName: "noble"}},
+	}
+
+	cfg.SetPathThresholds(paths)
+	assert.Equal(t, 168*time.Hour, paths[0].ClientExpiryThreshold)
+	assert.Equal(t, time.Duration(0), paths[1].ClientExpiryThreshold)
+}
+
+func TestEscrowIncludes(t *testing.T) {
+	path := &IBCData{Chain1: IBCChainMeta{ChainName: "archway"}, Chain2: IBCChainMeta{ChainName: "osmosis"}}
+
+	assert.True(t, (&Escrow{}).Includes(path))
+	assert.True(t, (&Escrow{Paths: []string{"osmosis-archway"}}).Includes(path))
+	assert.False(t, (&Escrow{Paths: []string{"archway-noble"}}).Includes(path))
+}
+
+func TestRemediationValidation(t *testing.T) {
+	cfg := Config{
+		RPCs: []*RPC{
+			{ChainName: "archway", ChainID: "archway-1", URL: "https://rpc.mainnet.archway.io:443", Key: "relayer", AccountPrefix: "archway", GasPrices: "900000000000aarch"},
+			{ChainName: "osmosis", ChainID: "osmosis-1", URL: "https://rpc.osmosis.zone:443"},
+		},
+		Keyring: &Keyring{Backend: "ledger"},
+		Remediation: &Remediation{
+			Paths: []string{"osmosis-archway", "archway-noble"},
+		},
+	}
+
+	msgs := []string{}
+	for _, err := range cfg.ValidationErrors() {
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Len(t, msgs, 3)
+	assert.Contains(t, msgs[0], "Config.Keyring.Backend")
+	assert.Contains(t, msgs[0], "Config.Remediation.AuditLog")
+	assert.Equal(t, `RPC config of chain "osmosis" needs key, accountPrefix and gasPrices for remediation path "osmosis-archway"`, msgs[1])
+	assert.Equal(t, `remediation path "archway-noble" doesn't match configured RPC chains`, msgs[2])
+
+	assert.True(t, cfg.Remediation.Allows("archway", "osmosis"))
+	assert.False(t, cfg.Remediation.Allows("archway", "axelar"))
+	assert.Equal(t, defaultRemediationInterval, cfg.Remediation.GetInterval())
+	assert.Equal(t, defaultFlushMaxPackets, (&PacketFlush{}).GetMaxPackets())
+}
+
+func TestForwardRoutesValidation(t *testing.T) {
+	cfg := Config{
+		RPCs: []*RPC{
+			{ChainName: "archway", ChainID: "archway-1", URL: "https://rpc.mainnet.archway.io:443"},
+			{ChainName: "osmosis", ChainID: "osmosis-1", URL: "https://rpc.osmosis.zone:443"},
+		},
+		ForwardRoutes: []*ForwardRoute{
+			{Name: "archway-noble", Chains: []string{"archway", "osmosis", "noble"}},
+			{Name: "archway-noble", Chains: []string{"archway", "osmosis", "osmosis"}},
+			{Name: "short", Chains: []string{"archway", "osmosis"}},
+		},
+	}
+
+	msgs := []string{}
+	for _, err := range cfg.ValidationErrors() {
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Len(t, msgs, 4)
+	assert.Contains(t, msgs[0], "Config.ForwardRoutes[2].Chains")
+	assert.Equal(t, `missing RPC config for chain: noble of route "archway-noble"`, msgs[1])
+	assert.Equal(t, `duplicate route "archway-noble"`, msgs[2])
+	assert.Equal(t, `route "archway-noble" has hop from osmosis to itself`, msgs[3])
+
+	assert.Equal(t, []Hop{{Src: "archway", Dst: "osmosis"}, {Src: "osmosis", Dst: "noble"}}, cfg.ForwardRoutes[0].Hops())
+}
@@ -0,0 +1,67 @@
+package config
+
+import "fmt"
+
+// ForwardRoute is a multi-hop route of transfers forwarded by packet
+// forward middleware through registry paths between consecutive chains.
+type ForwardRoute struct {
+	Name string `yaml:"name" validate:"required"`
+	// Chains in transfer order, e.g. archway, osmosis, noble.
+	Chains []string `yaml:"chains" validate:"min=3,dive,required"`
+}
+
+// Hop is a transfer between two consecutive chains of a route.
+type Hop struct {
+	Src string
+	Dst string
+}
+
+// Path returns name of the registry path of hop in <src>-<dst> format.
+func (h Hop) Path() string {
+	return h.Src + "-" + h.Dst
+}
+
+// Hops returns transfers between consecutive chains of route.
+func (r *ForwardRoute) Hops() []Hop {
+	hops := []Hop{}
+
+	for i := 1; i < len(r.Chains); i++ {
+		hops = append(hops, Hop{Src: r.Chains[i-1], Dst: r.Chains[i]})
+	}
+
+	return hops
+}
+
+// forwardRoutesValidationErrors returns problems of routes not covered by
+// struct validation.
+func forwardRoutesValidationErrors(routes []*ForwardRoute, rpcs []*RPC) []error {
+	errs := []error{}
+	names := map[string]bool{}
+	chains := map[string]bool{}
+
+	for _, rpc := range rpcs {
+		chains[rpc.ChainName] = true
+	}
+
+	for _, r := range routes {
+		if names[r.Name] {
+			errs = append(errs, fmt.Errorf("duplicate route %q", r.Name))
+		}
+
+		names[r.Name] = true
+
+		for _, c := range r.Chains {
+			if c != "" && !chains[c] {
+				errs = append(errs, fmt.Errorf(ErrMissingRPCConfigMsg+" of route %q", c, r.Name))
+			}
+		}
+
+		for _, h := range r.Hops() {
+			if h.Src == h.Dst {
+				errs = append(errs, fmt.Errorf("route %q has hop from %s to itself", r.Name, h.Src))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// DiscordIDPattern matches valid Discord user IDs.
+var DiscordIDPattern = regexp.MustCompile(`^\d+$`)
+
+// LintIBCPaths checks IBC registry JSON files in dir and returns every
+// problem found. Each file is validated against the JSON schema declared in
+// its $schema field, resolved relative to the file. Chain names are
+// cross-checked against rpcs and duplicate paths, duplicate channels and
+// malformed Discord IDs are reported.
+func LintIBCPaths(dir string, rpcs *map[string]RPC) []error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+	compiler := jsonschema.NewCompiler()
+	// Registry files are linted offline, only local schemas are loaded.
+	compiler.LoadURL = func(s string) (_ io.ReadCloser, err error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.Scheme != "file" {
+			return nil, fmt.Errorf("loading remote schema %s is not supported", s)
+		}
+
+		return os.Open(filepath.FromSlash(u.Path))
+	}
+
+	paths := map[string]string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ibcPathSuffix) {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ibc := &IBCData{}
+		if err := json.Unmarshal(content, ibc); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+
+		for _, err := range lintSchema(compiler, file, content, ibc.Schema) {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+		}
+
+		for _, err := range lintIBCData(ibc, rpcs) {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+		}
+
+		key := pathKey(ibc)
+		if other, ok := paths[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate path %s, already defined in %s", file, ibc.Name(), other))
+		} else {
+			paths[key] = file
+		}
+	}
+
+	return errs
+}
+
+func lintSchema(compiler *jsonschema.Compiler, file string, content []byte, schemaRef string) []error {
+	if schemaRef == "" {
+		return []error{fmt.Errorf("missing $schema")}
+	}
+
+	schemaPath := schemaRef
+	if !filepath.IsAbs(schemaPath) {
+		schemaPath = filepath.Join(filepath.Dir(file), schemaPath)
+	}
+
+	absPath, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return []error{err}
+	}
+
+	schema, err := compiler.Compile((&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String())
+	if err != nil {
+		return []error{fmt.Errorf("invalid $schema %s: %w", schemaRef, err)}
+	}
+
+	var v any
+	if err := json.Unmarshal(content, &v); err != nil {
+		return []error{err}
+	}
+
+	err = schema.Validate(v)
+	if err == nil {
+		return nil
+	}
+
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []error{err}
+	}
+
+	errs := []error{}
+
+	for _, cause := range leafCauses(ve) {
+		errs = append(errs, fmt.Errorf("schema: %s: %s", cause.InstanceLocation, cause.Message))
+	}
+
+	return errs
+}
+
+// leafCauses flattens validation error into its most specific causes.
+func leafCauses(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+
+	causes := []*jsonschema.ValidationError{}
+	for _, c := range ve.Causes {
+		causes = append(causes, leafCauses(c)...)
+	}
+
+	return causes
+}
+
+func lintIBCData(ibc *IBCData, rpcs *map[string]RPC) []error {
+	errs := []error{}
+
+	for _, chainName := range []string{ibc.Chain1.ChainName, ibc.Chain2.ChainName} {
+		if _, ok := (*rpcs)[chainName]; !ok {
+			errs = append(errs, fmt.Errorf(ErrMissingRPCConfigMsg, chainName))
+		}
+	}
+
+	chain1Channels := map[string]bool{}
+	chain2Channels := map[string]bool{}
+
+	for _, c := range ibc.Channels {
+		// Wildcard channel IDs, e.g. of ICA channels, stand for many
+		// channels and may repeat.
+		chain1Channel := c.Chain1.PortID + "/" + c.Chain1.ChannelID
+		if chain1Channels[chain1Channel] && !strings.Contains(c.Chain1.ChannelID, "*") {
+			errs = append(errs, fmt.Errorf("duplicate channel %s on %s", chain1Channel, ibc.Chain1.ChainName))
+		}
+
+		chain1Channels[chain1Channel] = true
+
+		chain2Channel := c.Chain2.PortID + "/" + c.Chain2.ChannelID
+		if chain2Channels[chain2Channel] && !strings.Contains(c.Chain2.ChannelID, "*") {
+			errs = append(errs, fmt.Errorf("duplicate channel %s on %s", chain2Channel, ibc.Chain2.ChainName))
+		}
+
+		chain2Channels[chain2Channel] = true
+	}
+
+	for _, op := range ibc.Operators {
+		if op.Discord.ID != "" && !DiscordIDPattern.MatchString(op.Discord.ID) {
+			errs = append(errs, fmt.Errorf("malformed discord id %q for operator %s", op.Discord.ID, op.Name))
+		}
+	}
+
+	return errs
+}
+
+// pathKey identifies IBC path by its chains regardless of their order.
+func pathKey(ibc *IBCData) string {
+	chains := []string{ibc.Chain1.ChainName, ibc.Chain2.ChainName}
+	sort.Strings(chains)
+
+	return strings.Join(chains, "-")
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DiscordReceiver is the name of receiver of top level discord config.
+	DiscordReceiver = "discord"
+
+	defaultNotificationsInterval       = 5 * time.Minute
+	defaultNotificationsRepeatInterval = 4 * time.Hour
+)
+
+// Notifications configures built-in alert evaluation and notifications.
+type Notifications struct {
+	// Interval between collections and rules evaluations.
+	Interval time.Duration `yaml:"interval"`
+	// RepeatInterval after which still firing alert is notified again.
+	RepeatInterval time.Duration `yaml:"repeatInterval"`
+	// Discord receives every alert.
+	Discord   *DiscordWebhook    `yaml:"discord"`
+	Receivers []*Receiver        `yaml:"receivers" validate:"dive"`
+	Routes    []*Route           `yaml:"routes" validate:"dive"`
+	Rules     *NotificationRules `yaml:"rules"`
+}
+
+type DiscordWebhook struct {
+	WebhookURL string `yaml:"webhookURL" env:"DISCORD_WEBHOOK_URL" validate:"required,http_url"`
+}
+
+type SlackWebhook struct {
+	WebhookURL string `yaml:"webhookURL" validate:"required,http_url"`
+}
+
+type PagerDuty struct {
+	RoutingKey string `yaml:"routingKey" validate:"required"`
+	// URL of Events API v2, defaults to PagerDuty cloud.
+	URL      string `yaml:"url" validate:"omitempty,http_url"`
+	Severity string `yaml:"severity" validate:"omitempty,oneof=critical error warning info"`
+}
+
+type Webhook struct {
+	URL string `yaml:"url" validate:"required,http_url"`
+}
+
+// Receiver is a named notification destination with exactly one backend.
+type Receiver struct {
+	Name      string          `yaml:"name" validate:"required"`
+	Discord   *DiscordWebhook `yaml:"discord"`
+	Slack     *SlackWebhook   `yaml:"slack"`
+	PagerDuty *PagerDuty      `yaml:"pagerduty"`
+	Webhook   *Webhook        `yaml:"webhook"`
+}
+
+// Route sends alerts matching all non-empty match fields to Receiver.
+type Route struct {
+	Receiver string     `yaml:"receiver" validate:"required"`
+	Match    RouteMatch `yaml:"match"`
+}
+
+// RouteMatch matches alerts by any of the listed values of each field.
+type RouteMatch struct {
+	// Chains are chain names from rpc config.
+	Chains []string `yaml:"chains"`
+	// Paths are IBC paths in <chain1>-<chain2> format.
+	Paths []string `yaml:"paths"`
+	// Operators are operator names from the IBC registry.
+	Operators []string `yaml:"operators"`
+	// Tags are account tags.
+	Tags []string `yaml:"tags"`
+}
+
+type NotificationRules struct {
+	ClientExpiry  *ClientExpiryRule   `yaml:"clientExpiry"`
+	StuckPackets  *StuckPacketsRule   `yaml:"stuckPackets"`
+	WalletBalance []WalletBalanceRule `yaml:"walletBalance" validate:"dive"`
+}
+
+// ClientExpiryRule fires when a client expires within given duration.
+type ClientExpiryRule struct {
+	Within time.Duration `yaml:"within" validate:"required"`
+}
+
+// StuckPacketsRule fires when a channel has more than Count stuck packets
+// for at least For.
+type StuckPacketsRule struct {
+	Count int           `yaml:"count" validate:"gte=0"`
+	For   time.Duration `yaml:"for"`
+}
+
+// WalletBalanceRule fires when balance of an account in Denom is below Min
+// given in base denom units.
+type WalletBalanceRule struct {
+	Denom string `yaml:"denom" validate:"required"`
+	Min   string `yaml:"min" validate:"required,numeric"`
+}
+
+// GetInterval returns evaluation interval with default applied.
+func (n *Notifications) GetInterval() time.Duration {
+	if n.Interval == 0 {
+		return defaultNotificationsInterval
+	}
+
+	return n.Interval
+}
+
+// GetRepeatInterval returns repeat interval with default applied.
+func (n *Notifications) GetRepeatInterval() time.Duration {
+	if n.RepeatInterval == 0 {
+		return defaultNotificationsRepeatInterval
+	}
+
+	return n.RepeatInterval
+}
+
+// validationErrors checks receivers and routes consistency.
+func (n *Notifications) validationErrors() []error {
+	errs := []error{}
+	receivers := map[string]bool{}
+
+	for _, r := range n.Receivers {
+		backends := 0
+
+		for _, configured := range []bool{r.Discord != nil, r.Slack != nil, r.PagerDuty != nil, r.Webhook != nil} {
+			if configured {
+				backends++
+			}
+		}
+
+		if backends != 1 {
+			errs = append(errs, fmt.Errorf("receiver %q must have exactly one backend, got %d", r.Name, backends))
+		}
+
+		if receivers[r.Name] {
+			errs = append(errs, fmt.Errorf("duplicate receiver %q", r.Name))
+		}
+
+		if n.Discord != nil && r.Name == DiscordReceiver {
+			errs = append(errs, fmt.Errorf("receiver name %q is reserved for top level discord config", r.Name))
+		}
+
+		receivers[r.Name] = true
+	}
+
+	for _, r := range n.Routes {
+		if !receivers[r.Receiver] {
+			errs = append(errs, fmt.Errorf("route to unknown receiver %q", r.Receiver))
+		}
+	}
+
+	return errs
+}
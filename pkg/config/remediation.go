@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRemediationInterval = 5 * time.Minute
+	defaultFlushMaxPackets     = 50
+)
+
+// Keyring configures keys signing remediation transactions.
+type Keyring struct {
+	// Backend of the keyring, defaults to test.
+	Backend string `yaml:"backend" validate:"omitempty,oneof=os file kwallet pass test memory"`
+	// Home is relayer home directory, keys of a chain are kept in
+	// <home>/keys/<chain-id> like rly does.
+	Home string `yaml:"home"`
+}
+
+// Remediation enables transactions fixing problems of owned IBC paths.
+type Remediation struct {
+	// Paths allowlist in <chain1>-<chain2> format, chains can be given in any
+	// order. Other paths are never touched.
+	Paths []string `yaml:"paths" validate:"required,min=1,dive,required"`
+	// Interval between evaluations.
+	Interval time.Duration `yaml:"interval" validate:"gte=0"`
+	// DryRun only records transactions that would be sent to audit log.
+	DryRun bool `yaml:"dryRun"`
+	// AuditLog is a file every remediation is appended to as a JSON line.
+	AuditLog string `yaml:"auditLog" validate:"required"`
+	// Memo of sent transactions.
+	Memo         string        `yaml:"memo"`
+	ClientUpdate *ClientUpdate `yaml:"clientUpdate"`
+	PacketFlush  *PacketFlush  `yaml:"packetFlush"`
+}
+
+// ClientUpdate refreshes clients of allowlisted paths nearing expiry.
+type ClientUpdate struct {
+	// Threshold before expiry within which clients are updated.
+	Threshold time.Duration `yaml:"threshold" validate:"gt=0"`
+}
+
+// PacketFlush relays packets of allowlisted paths stuck for too long.
+type PacketFlush struct {
+	// MinAge is how long a channel must have stuck packets to be flushed.
+	MinAge time.Duration `yaml:"minAge" validate:"gt=0"`
+	// MaxPackets caps packets relayed per evaluation across all paths.
+	MaxPackets int `yaml:"maxPackets" validate:"gte=0"`
+}
+
+// GetMaxPackets returns MaxPackets or its default.
+func (f *PacketFlush) GetMaxPackets() int {
+	if f.MaxPackets == 0 {
+		return defaultFlushMaxPackets
+	}
+
+	return f.MaxPackets
+}
+
+// GetInterval returns evaluation interval with default applied.
+func (r *Remediation) GetInterval() time.Duration {
+	if r.Interval == 0 {
+		return defaultRemediationInterval
+	}
+
+	return r.Interval
+}
+
+// Allows reports if path with chainA and chainB is allowlisted.
+func (r *Remediation) Allows(chainA, chainB string) bool {
+	for _, p := range r.Paths {
+		if p == chainA+"-"+chainB || p == chainB+"-"+chainA {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validationErrors checks that chains of allowlisted paths have signing
+// keys.
+func (r *Remediation) validationErrors(rpcs []*RPC) []error {
+	errs := []error{}
+
+	for _, p := range r.Paths {
+		chains := pathChains(p, rpcs)
+		if chains == nil {
+			errs = append(errs, fmt.Errorf("remediation path %q doesn't match configured RPC chains", p))
+
+			continue
+		}
+
+		for _, rpc := range chains {
+			if rpc.Key == "" || rpc.AccountPrefix == "" || rpc.GasPrices == "" {
+				errs = append(errs, fmt.Errorf(
+					"RPC config of chain %q needs key, accountPrefix and gasPrices for remediation path %q",
+					rpc.ChainName, p,
+				))
+			}
+		}
+	}
+
+	return errs
+}
+
+// pathChains returns RPC configs of both chains of path name, nil if it
+// doesn't match any pair.
+func pathChains(name string, rpcs []*RPC) []*RPC {
+	for _, a := range rpcs {
+		for _, b := range rpcs {
+			if a != b && name == a.ChainName+"-"+b.ChainName {
+				return []*RPC{a, b}
+			}
+		}
+	}
+
+	return nil
+}
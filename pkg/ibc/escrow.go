@@ -0,0 +1,203 @@
+package ibc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	"github.com/cosmos/relayer/v2/relayer"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// Escrow is a denom held by ICS-20 escrow account of a channel end with
+// supply of its vouchers on the counterparty chain. Without exploits or
+// packets in flight the supply equals the escrowed amount.
+type Escrow struct {
+	ChainName             string
+	ChannelID             string
+	CounterpartyChainName string
+	CounterpartyChannelID string
+	Address               string
+	Denom                 string
+	Amount                math.Int
+	// BaseDenom and TracePath are the origin of IBC denoms. Native denoms
+	// are their own base denom with empty trace path.
+	BaseDenom string
+	TracePath string
+	// CounterpartyDenom is the voucher denom minted on the counterparty.
+	CounterpartyDenom  string
+	CounterpartySupply math.Int
+}
+
+// DenomLookup returns base denom and trace path of IBC denom on chain with
+// rpc.
+type DenomLookup func(ctx context.Context, rpc config.RPC, denom string) (baseDenom, tracePath string, err error)
+
+// escrowEnd is a chain end of a transfer channel.
+type escrowEnd struct {
+	chain                 *relayer.Chain
+	rpc                   config.RPC
+	chainName             string
+	prefix                string
+	channelID             string
+	counterparty          *relayer.Chain
+	counterpartyChainName string
+	counterpartyChannelID string
+}
+
+// GetEscrows queries escrow accounts of both ends of path transfer channels
+// and supply of their vouchers on counterparty chains. Denoms failed to
+// query are skipped and returned joined in the error. Traces of IBC denoms
+// are resolved with lookup, or queried from escrow chains when it is nil.
+func GetEscrows(
+	ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC, lookup DenomLookup,
+) ([]Escrow, error) {
+	rpcA := (*rpcs)[ibc.Chain1.ChainName]
+	rpcB := (*rpcs)[ibc.Chain2.ChainName]
+
+	chainA, err := chain.PrepChain(ctx, chain.Info{ChainID: rpcA.ChainID, RPCAddr: rpcA.URL, Timeout: rpcA.Timeout})
+	if err != nil {
+		return nil, fmt.Errorf("error: %w for %s", err, rpcA.ChainID)
+	}
+
+	chainB, err := chain.PrepChain(ctx, chain.Info{ChainID: rpcB.ChainID, RPCAddr: rpcB.URL, Timeout: rpcB.Timeout})
+	if err != nil {
+		return nil, fmt.Errorf("error: %w for %s", err, rpcB.ChainID)
+	}
+
+	operatorsA, operatorsB := []string{}, []string{}
+
+	for _, o := range ibc.Operators {
+		operatorsA = append(operatorsA, o.Chain1.Address)
+		operatorsB = append(operatorsB, o.Chain2.Address)
+	}
+
+	prefixA := accountPrefix(rpcA, operatorsA)
+	prefixB := accountPrefix(rpcB, operatorsB)
+
+	escrows := []Escrow{}
+	errs := []error{}
+
+	for _, c := range ibc.Channels {
+		if c.Chain1.PortID != transfertypes.PortID || c.Chain2.PortID != transfertypes.PortID ||
+			strings.Contains(c.Chain1.ChannelID, "*") || strings.Contains(c.Chain2.ChannelID, "*") {
+			continue
+		}
+
+		for _, e := range []escrowEnd{
+			{chainA, rpcA, ibc.Chain1.ChainName, prefixA, c.Chain1.ChannelID, chainB, ibc.Chain2.ChainName, c.Chain2.ChannelID},
+			{chainB, rpcB, ibc.Chain2.ChainName, prefixB, c.Chain2.ChannelID, chainA, ibc.Chain1.ChainName, c.Chain1.ChannelID},
+		} {
+			res, err := getEscrow(ctx, e, lookup)
+			escrows = append(escrows, res...)
+
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return escrows, errors.Join(errs...)
+}
+
+func getEscrow(ctx context.Context, e escrowEnd, lookup DenomLookup) ([]Escrow, error) {
+	if lookup == nil {
+		lookup = func(ctx context.Context, _ config.RPC, denom string) (string, string, error) {
+			trace, err := e.chain.ChainProvider.QueryDenomTrace(ctx, strings.TrimPrefix(denom, transfertypes.DenomPrefix+"/"))
+			if err != nil {
+				return "", "", fmt.Errorf("error: %w querying trace of %s on %s", err, denom, e.chainName)
+			}
+
+			return trace.BaseDenom, trace.Path, nil
+		}
+	}
+
+	if e.prefix == "" {
+		return nil, fmt.Errorf("unknown account prefix of %s, set accountPrefix of its rpc", e.chainName)
+	}
+
+	address, err := bech32.ConvertAndEncode(
+		e.prefix, transfertypes.GetEscrowAddress(transfertypes.PortID, e.channelID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	coins, err := e.chain.ChainProvider.QueryBalanceWithAddress(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w querying escrow %s of %s", err, address, e.chainName)
+	}
+
+	escrows := []Escrow{}
+	errs := []error{}
+
+	for _, coin := range coins {
+		path, base, tracePath := coin.Denom, coin.Denom, ""
+
+		// Vouchers of IBC denoms are minted from their full denom path, so
+		// escrows whose trace can't be resolved are skipped.
+		if strings.HasPrefix(coin.Denom, transfertypes.DenomPrefix+"/") {
+			base, tracePath, err = lookup(ctx, e.rpc, coin.Denom)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			path = transfertypes.DenomTrace{Path: tracePath, BaseDenom: base}.GetFullDenomPath()
+		}
+
+		escrow := Escrow{
+			ChainName:             e.chainName,
+			ChannelID:             e.channelID,
+			CounterpartyChainName: e.counterpartyChainName,
+			CounterpartyChannelID: e.counterpartyChannelID,
+			Address:               address,
+			Denom:                 coin.Denom,
+			BaseDenom:             base,
+			TracePath:             tracePath,
+			Amount:                coin.Amount,
+			CounterpartyDenom:     VoucherDenom(path, transfertypes.PortID, e.counterpartyChannelID),
+		}
+
+		escrow.CounterpartySupply, err = chain.QuerySupplyOf(ctx, e.counterparty, escrow.CounterpartyDenom)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(
+				"error: %w querying supply of %s on %s", err, escrow.CounterpartyDenom, e.counterpartyChainName,
+			))
+
+			continue
+		}
+
+		escrows = append(escrows, escrow)
+	}
+
+	return escrows, errors.Join(errs...)
+}
+
+// VoucherDenom returns denom of vouchers minted on chain receiving token
+// with full denom path on its channel with portID and channelID.
+func VoucherDenom(path, portID, channelID string) string {
+	return transfertypes.ParseDenomTrace(portID + "/" + channelID + "/" + path).IBCDenom()
+}
+
+// accountPrefix returns configured account prefix of chain with rpc or
+// prefix of the first valid operator address.
+func accountPrefix(rpc config.RPC, operators []string) string {
+	if rpc.AccountPrefix != "" {
+		return rpc.AccountPrefix
+	}
+
+	for _, address := range operators {
+		if prefix, _, err := bech32.DecodeAndConvert(address); err == nil {
+			return prefix
+		}
+	}
+
+	return ""
+}
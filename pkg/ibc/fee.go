@@ -0,0 +1,234 @@
+package ibc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	feetypes "github.com/cosmos/ibc-go/v7/modules/apps/29-fee/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+const incentivesPerPage = 100
+
+// Incentives are ICS-29 fees escrowed for packets sent from a channel end
+// which haven't been relayed yet.
+type Incentives struct {
+	ChainName             string
+	ChannelID             string
+	PortID                string
+	CounterpartyChainName string
+	CounterpartyChannelID string
+	// Packets is the number of incentivized packets.
+	Packets    int
+	RecvFee    sdk.Coins
+	AckFee     sdk.Coins
+	TimeoutFee sdk.Coins
+}
+
+// PayeeRegistration tells whether an operator registered ICS-29 payees on
+// a channel end. Payees are empty when not registered.
+type PayeeRegistration struct {
+	ChainName string
+	ChannelID string
+	Operator  string
+	// Address of the operator relayer on the chain.
+	Address string
+	// Payee receives ack and timeout fees paid on the chain.
+	Payee string
+	// CounterpartyPayee receives recv fees paid on the counterparty for
+	// packets delivered to the chain.
+	CounterpartyPayee string
+}
+
+// FeeInfo is ICS-29 state of fee-enabled channels of a path.
+type FeeInfo struct {
+	Incentives []Incentives
+	Payees     []PayeeRegistration
+}
+
+// feeEnd is a chain end of a fee-enabled channel.
+type feeEnd struct {
+	chain                 *relayer.Chain
+	chainName             string
+	channelID             string
+	portID                string
+	counterpartyChainName string
+	counterpartyChannelID string
+}
+
+// IsFeeEnabled reports if channel version negotiated ICS-29 fee middleware.
+func IsFeeEnabled(version string) bool {
+	metadata := feetypes.Metadata{}
+	if err := json.Unmarshal([]byte(version), &metadata); err != nil {
+		return false
+	}
+
+	return metadata.FeeVersion == feetypes.Version
+}
+
+// GetFeeInfo queries incentivized packets and payees of path operators of
+// both ends of fee-enabled path channels. Queries which fail are skipped
+// and returned joined in the error.
+func GetFeeInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC) (FeeInfo, error) {
+	info := FeeInfo{}
+	ends := []feeEnd{}
+
+	for _, c := range ibc.Channels {
+		if !IsFeeEnabled(c.Version) ||
+			strings.Contains(c.Chain1.ChannelID, "*") || strings.Contains(c.Chain2.ChannelID, "*") {
+			continue
+		}
+
+		ends = append(ends,
+			feeEnd{nil, ibc.Chain1.ChainName, c.Chain1.ChannelID, c.Chain1.PortID, ibc.Chain2.ChainName, c.Chain2.ChannelID},
+			feeEnd{nil, ibc.Chain2.ChainName, c.Chain2.ChannelID, c.Chain2.PortID, ibc.Chain1.ChainName, c.Chain1.ChannelID},
+		)
+	}
+
+	if len(ends) == 0 {
+		return info, nil
+	}
+
+	chains := map[string]*relayer.Chain{}
+
+	for _, name := range []string{ibc.Chain1.ChainName, ibc.Chain2.ChainName} {
+		rpc := (*rpcs)[name]
+
+		c, err := chain.PrepChain(ctx, chain.Info{ChainID: rpc.ChainID, RPCAddr: rpc.URL, Timeout: rpc.Timeout})
+		if err != nil {
+			return info, fmt.Errorf("error: %w for %s", err, rpc.ChainID)
+		}
+
+		chains[name] = c
+	}
+
+	errs := []error{}
+
+	for _, e := range ends {
+		e.chain = chains[e.chainName]
+
+		incentives, err := getIncentives(ctx, e)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			info.Incentives = append(info.Incentives, incentives)
+		}
+
+		for _, o := range ibc.Operators {
+			address := o.Chain1.Address
+			if e.chainName == ibc.Chain2.ChainName {
+				address = o.Chain2.Address
+			}
+
+			if address == "" {
+				continue
+			}
+
+			payee, err := getPayeeRegistration(ctx, e, o.Name, address)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			info.Payees = append(info.Payees, payee)
+		}
+	}
+
+	return info, errors.Join(errs...)
+}
+
+func getIncentives(ctx context.Context, e feeEnd) (Incentives, error) {
+	incentives := Incentives{
+		ChainName:             e.chainName,
+		ChannelID:             e.channelID,
+		PortID:                e.portID,
+		CounterpartyChainName: e.counterpartyChainName,
+		CounterpartyChannelID: e.counterpartyChannelID,
+		RecvFee:               sdk.Coins{},
+		AckFee:                sdk.Coins{},
+		TimeoutFee:            sdk.Coins{},
+	}
+
+	conn, err := chain.ClientConn(e.chain)
+	if err != nil {
+		return incentives, err
+	}
+
+	client := feetypes.NewQueryClient(conn)
+
+	// Responses have no next page key, so pages are requested by offset.
+	for offset := uint64(0); ; offset += incentivesPerPage {
+		res, err := client.IncentivizedPacketsForChannel(ctx, &feetypes.QueryIncentivizedPacketsForChannelRequest{
+			Pagination: &query.PageRequest{Offset: offset, Limit: incentivesPerPage},
+			PortId:     e.portID,
+			ChannelId:  e.channelID,
+		})
+		if err != nil {
+			return incentives, fmt.Errorf(
+				"error: %w querying incentivized packets of %s on %s", err, e.channelID, e.chainName,
+			)
+		}
+
+		for _, p := range res.IncentivizedPackets {
+			incentives.Packets++
+
+			for _, f := range p.PacketFees {
+				incentives.RecvFee = incentives.RecvFee.Add(f.Fee.RecvFee...)
+				incentives.AckFee = incentives.AckFee.Add(f.Fee.AckFee...)
+				incentives.TimeoutFee = incentives.TimeoutFee.Add(f.Fee.TimeoutFee...)
+			}
+		}
+
+		if len(res.IncentivizedPackets) < incentivesPerPage {
+			return incentives, nil
+		}
+	}
+}
+
+func getPayeeRegistration(ctx context.Context, e feeEnd, operator, address string) (PayeeRegistration, error) {
+	payee := PayeeRegistration{
+		ChainName: e.chainName,
+		ChannelID: e.channelID,
+		Operator:  operator,
+		Address:   address,
+	}
+
+	conn, err := chain.ClientConn(e.chain)
+	if err != nil {
+		return payee, err
+	}
+
+	client := feetypes.NewQueryClient(conn)
+
+	payeeRes, err := client.Payee(ctx, &feetypes.QueryPayeeRequest{ChannelId: e.channelID, Relayer: address})
+
+	switch {
+	case err == nil:
+		payee.Payee = payeeRes.PayeeAddress
+	case status.Code(err) != codes.NotFound:
+		return payee, fmt.Errorf("error: %w querying payee of %s on %s", err, address, e.chainName)
+	}
+
+	counterpartyRes, err := client.CounterpartyPayee(
+		ctx, &feetypes.QueryCounterpartyPayeeRequest{ChannelId: e.channelID, Relayer: address},
+	)
+
+	switch {
+	case err == nil:
+		payee.CounterpartyPayee = counterpartyRes.CounterpartyPayee
+	case status.Code(err) != codes.NotFound:
+		return payee, fmt.Errorf("error: %w querying counterparty payee of %s on %s", err, address, e.chainName)
+	}
+
+	return payee, nil
+}
@@ -2,12 +2,18 @@ package ibc
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
 	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 
 	"github.com/archway-network/relayer_exporter/pkg/chain"
@@ -15,7 +21,13 @@ import (
 	log "github.com/archway-network/relayer_exporter/pkg/logger"
 )
 
-const stateOpen = 3
+const (
+	stateOpen = 3
+
+	// clientUpdatesPerPage is how many of the most recent client updates are
+	// searched to find two at distinct heights.
+	clientUpdatesPerPage = 10
+)
 
 type ClientsInfo struct {
 	ChainA                 *relayer.Chain
@@ -24,6 +36,21 @@ type ClientsInfo struct {
 	ChainB                 *relayer.Chain
 	ChainBClientInfo       relayer.ClientStateInfo
 	ChainBClientExpiration time.Time
+	ChainAClientUpdate     ClientUpdate
+	ChainBClientUpdate     ClientUpdate
+}
+
+// ClientUpdate describes when a light client was updated.
+type ClientUpdate struct {
+	// ConsensusTimestamp is the timestamp of the consensus state at the
+	// latest client height.
+	ConsensusTimestamp time.Time
+	// LastUpdate is the block time of the most recent update_client event,
+	// zero if none was found.
+	LastUpdate time.Time
+	// Interval is the time between the two most recent updates, zero if
+	// fewer than two were found.
+	Interval time.Duration
 }
 
 type ChannelsInfo struct {
@@ -40,6 +67,95 @@ type Channel struct {
 		Source      int
 		Destination int
 	}
+	// Sequences of unrelayed packets sent from each end.
+	Sequences struct {
+		Source      []uint64
+		Destination []uint64
+	}
+	// TimedOutPackets are stuck packets already timed out on the
+	// counterparty, which need MsgTimeout instead of MsgRecvPacket.
+	TimedOutPackets struct {
+		Source      int
+		Destination int
+	}
+	// UnknownPackets are stuck packets whose timeout couldn't be queried.
+	UnknownPackets struct {
+		Source      int
+		Destination int
+	}
+	// NextSequences are sequence counters of each end, nil if they couldn't
+	// be queried.
+	NextSequences struct {
+		Source      *Sequences
+		Destination *Sequences
+	}
+}
+
+// Sequences are next sequence counters of a channel end.
+type Sequences struct {
+	Send uint64 `json:"send"`
+	Recv uint64 `json:"recv"`
+	Ack  uint64 `json:"ack"`
+}
+
+// MaxPacketLookups caps send packet queries per channel end and collection.
+// Packets over the cap are unknown until they're looked up by a later
+// collection.
+const MaxPacketLookups = 20
+
+// ErrPacketLookupSkipped is error of packets over MaxPacketLookups.
+const ErrPacketLookupSkipped = "packet lookup skipped over limit"
+
+// PacketLookup returns metadata of packets with sequences sent from channel
+// of chain with rpc.
+type PacketLookup func(
+	ctx context.Context, rpc config.RPC, channelID, portID string, sequences []uint64,
+) []Packet
+
+// chainTime is the latest height and block time of a chain.
+type chainTime struct {
+	height clienttypes.Height
+	time   time.Time
+}
+
+// Packet is a packet sent on a channel with metadata of its send_packet
+// event. Transfer fields are set for ICS-20 packets only.
+type Packet struct {
+	Sequence uint64 `json:"sequence"`
+	// TimeoutHeight in <revision>-<height> format, empty if not set.
+	TimeoutHeight    string     `json:"timeout_height,omitempty"`
+	TimeoutTimestamp *time.Time `json:"timeout_timestamp,omitempty"`
+	Sender           string     `json:"sender,omitempty"`
+	Receiver         string     `json:"receiver,omitempty"`
+	Denom            string     `json:"denom,omitempty"`
+	Amount           string     `json:"amount,omitempty"`
+	// Error is set when metadata couldn't be queried.
+	Error string `json:"error,omitempty"`
+}
+
+// Identified returns open channel c as seen from its source chain.
+func (c Channel) Identified() *chantypes.IdentifiedChannel {
+	var order chantypes.Order
+
+	switch c.Ordering {
+	case "none":
+		order = chantypes.NONE
+	case "unordered":
+		order = chantypes.UNORDERED
+	case "ordered":
+		order = chantypes.ORDERED
+	}
+
+	return &chantypes.IdentifiedChannel{
+		State:    stateOpen,
+		Ordering: order,
+		Counterparty: chantypes.Counterparty{
+			PortId:    c.DestinationPort,
+			ChannelId: c.Destination,
+		},
+		PortId:    c.SourcePort,
+		ChannelId: c.Source,
+	}
 }
 
 func GetClientsInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC) (clientsInfo ClientsInfo, err error) {
@@ -98,10 +214,99 @@ func GetClientsInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]c
 		return ClientsInfo{}, fmt.Errorf("%w path %v <-> %v", err, cdB, cdA)
 	}
 
+	// Update times are informational, failing to query them doesn't fail
+	// client expiry.
+	clientsInfo.ChainAClientUpdate, err = GetClientUpdate(ctx, chainA, clientsInfo.ChainAClientInfo)
+	if err != nil {
+		log.Error(err.Error(), zap.String("chain_id", cdA.ChainID), zap.String("client_id", cdA.ClientID))
+	}
+
+	clientsInfo.ChainBClientUpdate, err = GetClientUpdate(ctx, chainB, clientsInfo.ChainBClientInfo)
+	if err != nil {
+		log.Error(err.Error(), zap.String("chain_id", cdB.ChainID), zap.String("client_id", cdB.ClientID))
+	}
+
 	return clientsInfo, nil
 }
 
-func GetChannelsInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC) (ChannelsInfo, error) {
+// GetClientUpdate queries consensus state timestamp at the latest height of
+// the client hosted on host and block times of its most recent updates.
+// Update times are left zero when the host doesn't index transactions.
+func GetClientUpdate(ctx context.Context, host *relayer.Chain, info relayer.ClientStateInfo) (ClientUpdate, error) {
+	update := ClientUpdate{}
+
+	if info.LatestHeight == nil {
+		return update, fmt.Errorf("unknown latest height of client %s", host.ClientID())
+	}
+
+	latest, err := host.ChainProvider.QueryLatestHeight(ctx)
+	if err != nil {
+		return update, err
+	}
+
+	res, err := host.ChainProvider.QueryClientConsensusState(ctx, latest, host.ClientID(), info.LatestHeight)
+	if err != nil {
+		return update, fmt.Errorf("%w querying consensus state of client %s", err, host.ClientID())
+	}
+
+	cs, err := clienttypes.UnpackConsensusState(res.ConsensusState)
+	if err != nil {
+		return update, err
+	}
+
+	update.ConsensusTimestamp = time.Unix(0, int64(cs.GetTimestamp()))
+
+	query := fmt.Sprintf(
+		"%s.%s='%s'", clienttypes.EventTypeUpdateClient, clienttypes.AttributeKeyClientID, host.ClientID(),
+	)
+
+	txs, err := chain.TxSearch(ctx, host, query, 1, clientUpdatesPerPage, "desc")
+	if err != nil {
+		log.Debug("Failed to search client updates", zap.String("client_id", host.ClientID()), zap.Error(err))
+
+		return update, nil
+	}
+
+	heights := []int64{}
+
+	for _, tx := range txs.Txs {
+		if len(heights) == 0 || heights[len(heights)-1] != tx.Height {
+			heights = append(heights, tx.Height)
+		}
+
+		if len(heights) == 2 {
+			break
+		}
+	}
+
+	times := make([]time.Time, 0, len(heights))
+
+	for _, h := range heights {
+		t, err := host.ChainProvider.BlockTime(ctx, h)
+		if err != nil {
+			return update, fmt.Errorf("%w querying block %d time", err, h)
+		}
+
+		times = append(times, t)
+	}
+
+	if len(times) > 0 {
+		update.LastUpdate = times[0]
+	}
+
+	if len(times) > 1 {
+		update.Interval = times[0].Sub(times[1])
+	}
+
+	return update, nil
+}
+
+// GetChannelsInfo queries unrelayed packets of path channels and classifies
+// them by timeout using packet metadata from lookup. If lookup is nil
+// metadata is queried directly.
+func GetChannelsInfo(
+	ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC, lookup PacketLookup,
+) (ChannelsInfo, error) {
 	channelInfo := ChannelsInfo{}
 
 	// Init channel data
@@ -131,7 +336,7 @@ func GetChannelsInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]
 
 	chainA, err := chain.PrepChain(ctx, cdA)
 	if err != nil {
-		return ChannelsInfo{}, fmt.Errorf("error: %w for %+v", err, cdA)
+		return channelInfo, fmt.Errorf("error: %w for %+v", err, cdA)
 	}
 
 	cdB := chain.Info{
@@ -143,44 +348,187 @@ func GetChannelsInfo(ctx context.Context, ibc *config.IBCData, rpcs *map[string]
 
 	chainB, err := chain.PrepChain(ctx, cdB)
 	if err != nil {
-		return ChannelsInfo{}, fmt.Errorf("error: %w for %+v", err, cdB)
+		return channelInfo, fmt.Errorf("error: %w for %+v", err, cdB)
 	}
 
 	// test that RPC endpoints are working
-	if _, _, err := relayer.QueryLatestHeights(
-		ctx, chainA, chainB,
-	); err != nil {
+	heightA, heightB, err := relayer.QueryLatestHeights(ctx, chainA, chainB)
+	if err != nil {
 		return channelInfo, fmt.Errorf("error: %w for %v", err, cdA)
 	}
 
-	for i, c := range channelInfo.Channels {
-		var order chantypes.Order
-
-		switch c.Ordering {
-		case "none":
-			order = chantypes.NONE
-		case "unordered":
-			order = chantypes.UNORDERED
-		case "ordered":
-			order = chantypes.ORDERED
-		}
+	if lookup == nil {
+		lookup = func(ctx context.Context, rpc config.RPC, channelID, portID string, sequences []uint64) []Packet {
+			c := chainA
+			if rpc.ChainID == cdB.ChainID {
+				c = chainB
+			}
 
-		ch := chantypes.IdentifiedChannel{
-			State:    stateOpen,
-			Ordering: order,
-			Counterparty: chantypes.Counterparty{
-				PortId:    c.DestinationPort,
-				ChannelId: c.Destination,
-			},
-			PortId:    c.SourcePort,
-			ChannelId: c.Source,
+			return GetPackets(ctx, c, channelID, portID, sequences)
 		}
+	}
+
+	// Block times are queried only when there are stuck packets.
+	var timeA, timeB *chainTime
 
-		unrelayedSequences := relayer.UnrelayedSequences(ctx, chainA, chainB, &ch)
+	for i, c := range channelInfo.Channels {
+		unrelayedSequences := relayer.UnrelayedSequences(ctx, chainA, chainB, c.Identified())
 
 		channelInfo.Channels[i].StuckPackets.Source += len(unrelayedSequences.Src)
 		channelInfo.Channels[i].StuckPackets.Destination += len(unrelayedSequences.Dst)
+		channelInfo.Channels[i].Sequences.Source = unrelayedSequences.Src
+		channelInfo.Channels[i].Sequences.Destination = unrelayedSequences.Dst
+		channelInfo.Channels[i].NextSequences.Source = querySequences(ctx, chainA, c.Source, c.SourcePort)
+		channelInfo.Channels[i].NextSequences.Destination = querySequences(ctx, chainB, c.Destination, c.DestinationPort)
+
+		if len(unrelayedSequences.Src) > 0 {
+			if timeB == nil {
+				timeB = queryChainTime(ctx, chainB, heightB)
+			}
+
+			packets := lookup(ctx, (*rpcs)[ibc.Chain1.ChainName], c.Source, c.SourcePort, unrelayedSequences.Src)
+			channelInfo.Channels[i].TimedOutPackets.Source, channelInfo.Channels[i].UnknownPackets.Source =
+				classifyPackets(packets, timeB)
+		}
+
+		if len(unrelayedSequences.Dst) > 0 {
+			if timeA == nil {
+				timeA = queryChainTime(ctx, chainA, heightA)
+			}
+
+			packets := lookup(ctx, (*rpcs)[ibc.Chain2.ChainName], c.Destination, c.DestinationPort, unrelayedSequences.Dst)
+			channelInfo.Channels[i].TimedOutPackets.Destination, channelInfo.Channels[i].UnknownPackets.Destination =
+				classifyPackets(packets, timeA)
+		}
 	}
 
 	return channelInfo, nil
 }
+
+// querySequences returns next sequence counters of channel on chain c or
+// nil if any of them can't be queried.
+func querySequences(ctx context.Context, c *relayer.Chain, channelID, portID string) *Sequences {
+	seqs := &Sequences{}
+
+	for _, k := range []struct {
+		key   []byte
+		value *uint64
+	}{
+		{host.NextSequenceSendKey(portID, channelID), &seqs.Send},
+		{host.NextSequenceRecvKey(portID, channelID), &seqs.Recv},
+		{host.NextSequenceAckKey(portID, channelID), &seqs.Ack},
+	} {
+		value, err := chain.QueryIBCStore(ctx, c, k.key)
+		if err == nil && len(value) != 8 {
+			err = fmt.Errorf("invalid sequence %x", value)
+		}
+
+		if err != nil {
+			log.Error(
+				"Failed to query next sequence",
+				zap.String("chain_id", c.ChainID()),
+				zap.String("channel_id", channelID),
+				zap.String("key", string(k.key)),
+				zap.Error(err),
+			)
+
+			return nil
+		}
+
+		*k.value = binary.BigEndian.Uint64(value)
+	}
+
+	return seqs
+}
+
+// queryChainTime returns time of chain c at height. Time is left zero when
+// the block can't be queried, so only timeout heights are compared.
+func queryChainTime(ctx context.Context, c *relayer.Chain, height int64) *chainTime {
+	t, err := c.ChainProvider.BlockTime(ctx, height)
+	if err != nil {
+		log.Error("Failed to query block time", zap.String("chain_id", c.ChainID()), zap.Error(err))
+	}
+
+	return &chainTime{
+		height: clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(height)),
+		time:   t,
+	}
+}
+
+// classifyPackets returns counts of packets timed out on counterparty at
+// ct and packets whose metadata is unknown.
+func classifyPackets(packets []Packet, ct *chainTime) (timedOut, unknown int) {
+	for _, p := range packets {
+		switch {
+		case p.Error != "":
+			unknown++
+		case p.TimedOut(ct.height, ct.time):
+			timedOut++
+		}
+	}
+
+	return timedOut, unknown
+}
+
+// GetPackets queries metadata of packets with sequences sent from channel
+// on c. Packets failed to query or over MaxPacketLookups have Error set.
+func GetPackets(ctx context.Context, c *relayer.Chain, channelID, portID string, sequences []uint64) []Packet {
+	packets := make([]Packet, 0, len(sequences))
+
+	for i, seq := range sequences {
+		if i >= MaxPacketLookups {
+			packets = append(packets, Packet{Sequence: seq, Error: ErrPacketLookupSkipped})
+
+			continue
+		}
+
+		info, err := c.ChainProvider.QuerySendPacket(ctx, channelID, portID, seq)
+		if err != nil {
+			packets = append(packets, Packet{Sequence: seq, Error: err.Error()})
+
+			continue
+		}
+
+		packets = append(packets, NewPacket(info))
+	}
+
+	return packets
+}
+
+// TimedOut reports if packet timed out on counterparty at height and time.
+// Zero time is ignored.
+func (p Packet) TimedOut(height clienttypes.Height, t time.Time) bool {
+	if p.TimeoutHeight != "" {
+		timeout, err := clienttypes.ParseHeight(p.TimeoutHeight)
+		if err == nil && height.GTE(timeout) {
+			return true
+		}
+	}
+
+	return p.TimeoutTimestamp != nil && !t.IsZero() && !t.Before(*p.TimeoutTimestamp)
+}
+
+// NewPacket returns packet from send_packet event info, decoding ICS-20
+// data when possible.
+func NewPacket(info provider.PacketInfo) Packet {
+	packet := Packet{Sequence: info.Sequence}
+
+	if !info.TimeoutHeight.IsZero() {
+		packet.TimeoutHeight = info.TimeoutHeight.String()
+	}
+
+	if info.TimeoutTimestamp > 0 {
+		timeout := time.Unix(0, int64(info.TimeoutTimestamp)).UTC()
+		packet.TimeoutTimestamp = &timeout
+	}
+
+	data := transfertypes.FungibleTokenPacketData{}
+	if err := json.Unmarshal(info.Data, &data); err == nil && data.Denom != "" {
+		packet.Sender = data.Sender
+		packet.Receiver = data.Receiver
+		packet.Denom = data.Denom
+		packet.Amount = data.Amount
+	}
+
+	return packet
+}
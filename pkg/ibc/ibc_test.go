@@ -0,0 +1,165 @@
+package ibc
+
+import (
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+func TestNewPacket(t *testing.T) {
+	timeout := time.Unix(1700000000, 0).UTC()
+
+	testCases := []struct {
+		name     string
+		info     provider.PacketInfo
+		expected Packet
+	}{
+		{
+			name: "Transfer",
+			info: provider.PacketInfo{
+				Sequence:         7,
+				TimeoutHeight:    clienttypes.NewHeight(1, 100),
+				TimeoutTimestamp: uint64(timeout.UnixNano()),
+				Data:             []byte(`{"denom":"aarch","amount":"10","sender":"archway1a","receiver":"osmo1b"}`),
+			},
+			expected: Packet{
+				Sequence:         7,
+				TimeoutHeight:    "1-100",
+				TimeoutTimestamp: &timeout,
+				Sender:           "archway1a",
+				Receiver:         "osmo1b",
+				Denom:            "aarch",
+				Amount:           "10",
+			},
+		},
+		{
+			name:     "Not Transfer",
+			info:     provider.PacketInfo{Sequence: 8, Data: []byte(`{"foo":"bar"}`)},
+			expected: Packet{Sequence: 8},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NewPacket(tc.info))
+		})
+	}
+}
+
+func TestPacketTimedOut(t *testing.T) {
+	timeout := time.Unix(1700000000, 0)
+	height := clienttypes.NewHeight(1, 100)
+
+	testCases := []struct {
+		name     string
+		packet   Packet
+		time     time.Time
+		expected bool
+	}{
+		{
+			name:     "Height Reached",
+			packet:   Packet{TimeoutHeight: "1-100"},
+			expected: true,
+		},
+		{
+			name:     "Height Not Reached",
+			packet:   Packet{TimeoutHeight: "1-101"},
+			expected: false,
+		},
+		{
+			name:     "Timestamp Reached",
+			packet:   Packet{TimeoutTimestamp: &timeout},
+			time:     timeout,
+			expected: true,
+		},
+		{
+			name:     "Timestamp Not Reached",
+			packet:   Packet{TimeoutTimestamp: &timeout},
+			time:     timeout.Add(-time.Second),
+			expected: false,
+		},
+		{
+			name:     "Unknown Chain Time",
+			packet:   Packet{TimeoutTimestamp: &timeout},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.packet.TimedOut(height, tc.time))
+		})
+	}
+}
+
+func TestClassifyPackets(t *testing.T) {
+	timeout := time.Unix(1700000000, 0)
+	ct := &chainTime{height: clienttypes.NewHeight(1, 100), time: timeout}
+
+	packets := []Packet{
+		{Sequence: 1, TimeoutHeight: "1-50"},
+		{Sequence: 2, TimeoutTimestamp: &timeout},
+		{Sequence: 3, TimeoutHeight: "1-200"},
+		{Sequence: 4, Error: "not found"},
+	}
+
+	timedOut, unknown := classifyPackets(packets, ct)
+	assert.Equal(t, 2, timedOut)
+	assert.Equal(t, 1, unknown)
+}
+
+func TestVoucherDenom(t *testing.T) {
+	// ATOM on Osmosis.
+	assert.Equal(
+		t,
+		"ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		VoucherDenom("uatom", "transfer", "channel-0"),
+	)
+}
+
+func TestAccountPrefix(t *testing.T) {
+	operators := []string{"invalid", "archway1l2al7y78500h5akvgt8exwnkpmf2zmk8ky9ht3"}
+
+	assert.Equal(t, "archway", accountPrefix(config.RPC{}, operators))
+	assert.Equal(t, "custom", accountPrefix(config.RPC{AccountPrefix: "custom"}, operators))
+	assert.Equal(t, "", accountPrefix(config.RPC{}, nil))
+}
+
+func TestIsFeeEnabled(t *testing.T) {
+	assert.True(t, IsFeeEnabled(`{"fee_version":"ics29-1","app_version":"ics20-1"}`))
+	assert.False(t, IsFeeEnabled(`{"app_version":"ics20-1"}`))
+	assert.False(t, IsFeeEnabled("ics20-1"))
+}
+
+func TestIsICAChannel(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ports    [2]string
+		expected bool
+	}{
+		{name: "Controller On Chain 1", ports: [2]string{"icacontroller-*", "icahost"}, expected: true},
+		{name: "Controller On Chain 2", ports: [2]string{"icahost", "icacontroller-archway1a"}, expected: true},
+		{name: "Transfer", ports: [2]string{"transfer", "transfer"}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Channel{}
+			c.Chain1.PortID = tc.ports[0]
+			c.Chain2.PortID = tc.ports[1]
+
+			assert.Equal(t, tc.expected, IsICAChannel(c))
+		})
+	}
+}
+
+func TestStateName(t *testing.T) {
+	assert.Equal(t, "open", StateName(chantypes.OPEN))
+	assert.Equal(t, "closed", StateName(chantypes.CLOSED))
+}
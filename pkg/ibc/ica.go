@@ -0,0 +1,140 @@
+package ibc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	icatypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// ICAChannel is a channel of an interchain account opened by owner on the
+// controller chain.
+type ICAChannel struct {
+	ControllerChainName string
+	HostChainName       string
+	// ConnectionID of the controller chain end.
+	ConnectionID  string
+	Owner         string
+	PortID        string
+	ChannelID     string
+	HostChannelID string
+	// State of the controller channel end, e.g. open or closed.
+	State string
+	// PendingPackets are packets sent on an open channel which haven't
+	// been relayed yet.
+	PendingPackets int
+}
+
+// IsICAChannel reports if registry channel c connects interchain accounts
+// controller and host ports.
+func IsICAChannel(c config.Channel) bool {
+	return (strings.HasPrefix(c.Chain1.PortID, icatypes.ControllerPortPrefix) && c.Chain2.PortID == icatypes.HostPortID) ||
+		(strings.HasPrefix(c.Chain2.PortID, icatypes.ControllerPortPrefix) && c.Chain1.PortID == icatypes.HostPortID)
+}
+
+// GetICAChannels enumerates interchain accounts controller channels on
+// connections of path ends which have ICA channels in the registry,
+// including channels registered with wildcard IDs.
+func GetICAChannels(ctx context.Context, ibc *config.IBCData, rpcs *map[string]config.RPC) ([]ICAChannel, error) {
+	// Host chain ends keyed by controller chain ends.
+	controllers := map[config.IBCChainMeta]config.IBCChainMeta{}
+
+	for _, c := range ibc.Channels {
+		if !IsICAChannel(c) {
+			continue
+		}
+
+		if strings.HasPrefix(c.Chain1.PortID, icatypes.ControllerPortPrefix) {
+			controllers[ibc.Chain1] = ibc.Chain2
+		} else {
+			controllers[ibc.Chain2] = ibc.Chain1
+		}
+	}
+
+	channels := []ICAChannel{}
+	errs := []error{}
+
+	for controller, host := range controllers {
+		res, err := getControllerChannels(ctx, controller, host, rpcs)
+		channels = append(channels, res...)
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return channels, errors.Join(errs...)
+}
+
+func getControllerChannels(
+	ctx context.Context, ctrl, host config.IBCChainMeta, rpcs *map[string]config.RPC,
+) ([]ICAChannel, error) {
+	cdA := chain.Info{
+		ChainID:  (*rpcs)[ctrl.ChainName].ChainID,
+		RPCAddr:  (*rpcs)[ctrl.ChainName].URL,
+		Timeout:  (*rpcs)[ctrl.ChainName].Timeout,
+		ClientID: ctrl.ClientID,
+	}
+
+	controller, err := chain.PrepChain(ctx, cdA)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w for %+v", err, cdA)
+	}
+
+	cdB := chain.Info{
+		ChainID:  (*rpcs)[host.ChainName].ChainID,
+		RPCAddr:  (*rpcs)[host.ChainName].URL,
+		Timeout:  (*rpcs)[host.ChainName].Timeout,
+		ClientID: host.ClientID,
+	}
+
+	hostChain, err := chain.PrepChain(ctx, cdB)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w for %+v", err, cdB)
+	}
+
+	identified, err := controller.ChainProvider.QueryConnectionChannels(ctx, 0, ctrl.ConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w querying channels of %s on %s", err, ctrl.ConnectionID, ctrl.ChainName)
+	}
+
+	channels := []ICAChannel{}
+
+	for _, c := range identified {
+		owner, ok := strings.CutPrefix(c.PortId, icatypes.ControllerPortPrefix)
+		if !ok {
+			continue
+		}
+
+		channel := ICAChannel{
+			ControllerChainName: ctrl.ChainName,
+			HostChainName:       host.ChainName,
+			ConnectionID:        ctrl.ConnectionID,
+			Owner:               owner,
+			PortID:              c.PortId,
+			ChannelID:           c.ChannelId,
+			HostChannelID:       c.Counterparty.ChannelId,
+			State:               StateName(c.State),
+		}
+
+		if c.State == chantypes.OPEN {
+			channel.PendingPackets = len(relayer.UnrelayedSequences(ctx, controller, hostChain, c).Src)
+		}
+
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// StateName returns lower case channel state without prefix, e.g. open.
+func StateName(state chantypes.State) string {
+	return strings.ToLower(strings.TrimPrefix(state.String(), "STATE_"))
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"flag"
 	"time"
 
 	"go.uber.org/zap"
@@ -62,3 +63,11 @@ func SetLevel(l zapcore.Level) {
 func LevelFlag() *zapcore.Level {
 	return zap.LevelFlag("log-level", zapcore.InfoLevel, "Set log level")
 }
+
+// LevelFlagSet defines log level flag on a subcommand flag set.
+func LevelFlagSet(fs *flag.FlagSet) *zapcore.Level {
+	l := zapcore.InfoLevel
+	fs.Var(&l, "log-level", "Set log level")
+
+	return &l
+}
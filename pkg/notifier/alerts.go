@@ -0,0 +1,243 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+const (
+	RuleClientExpiry  = "client_expiry"
+	RuleStuckPackets  = "stuck_packets"
+	RuleWalletBalance = "wallet_balance"
+)
+
+// Alert is a rule condition met by a single series. Labels match labels of
+// the corresponding Prometheus series.
+type Alert struct {
+	Rule       string            `json:"rule"`
+	Summary    string            `json:"summary"`
+	Labels     map[string]string `json:"labels"`
+	DiscordIDs []string          `json:"discord_ids"`
+	// Path, Chains, Operators and Tags are used for routing.
+	Path      string   `json:"path,omitempty"`
+	Chains    []string `json:"chains"`
+	Operators []string `json:"operators"`
+	Tags      []string `json:"tags"`
+	// For is how long the condition must hold before the alert fires.
+	For time.Duration `json:"-"`
+}
+
+// Key identifies alert for de-duplication.
+func (a Alert) Key() string {
+	names := make([]string, 0, len(a.Labels))
+	for name := range a.Labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	key := a.Rule
+	for _, name := range names {
+		key += "," + name + "=" + a.Labels[name]
+	}
+
+	return key
+}
+
+func discordIDs(ops []config.Operator) []string {
+	ids := []string{}
+
+	for _, op := range ops {
+		if config.DiscordIDPattern.MatchString(op.Discord.ID) {
+			ids = append(ids, op.Discord.ID)
+		}
+	}
+
+	return ids
+}
+
+func operatorNames(ops []config.Operator) []string {
+	names := []string{}
+
+	for _, op := range ops {
+		if op.Name != "" {
+			names = append(names, op.Name)
+		}
+	}
+
+	return names
+}
+
+// pathAlert returns alert with routing attributes of path p.
+func pathAlert(rule string, p collector.PathStatus, ids []string) Alert {
+	return Alert{
+		Rule:       rule,
+		DiscordIDs: ids,
+		Path:       p.Name,
+		Chains:     []string{p.Chain1.ChainName, p.Chain2.ChainName},
+		Operators:  operatorNames(p.Operators),
+		Tags:       []string{},
+	}
+}
+
+// evaluate returns alerts for all series meeting rule conditions.
+func evaluate(
+	rules *config.NotificationRules,
+	paths []collector.PathStatus,
+	accounts []collector.AccountStatus,
+	now time.Time,
+) []Alert {
+	alerts := []Alert{}
+
+	if rules == nil {
+		return alerts
+	}
+
+	for _, p := range paths {
+		ids := discordIDs(p.Operators)
+
+		if rules.ClientExpiry != nil {
+			alerts = append(alerts, clientExpiryAlerts(rules.ClientExpiry, p, ids, now)...)
+		}
+
+		if rules.StuckPackets != nil {
+			alerts = append(alerts, stuckPacketsAlerts(rules.StuckPackets, p, ids)...)
+		}
+	}
+
+	for _, rule := range rules.WalletBalance {
+		alerts = append(alerts, walletBalanceAlerts(rule, accounts)...)
+	}
+
+	return alerts
+}
+
+func clientExpiryAlerts(rule *config.ClientExpiryRule, p collector.PathStatus, ids []string, now time.Time) []Alert {
+	alerts := []Alert{}
+
+	for _, c := range [][2]collector.ChainStatus{{p.Chain1, p.Chain2}, {p.Chain2, p.Chain1}} {
+		src, dst := c[0], c[1]
+
+		// Thresholds configured for the client take precedence like in
+		// generated Prometheus rules.
+		within := rule.Within
+		if src.ClientExpiryThreshold != 0 {
+			within = src.ClientExpiryThreshold
+		}
+
+		if src.ClientExpiry == nil || src.ClientExpiry.Sub(now) >= within {
+			continue
+		}
+
+		alert := pathAlert(RuleClientExpiry, p, ids)
+		alert.Summary = fmt.Sprintf(
+			"Client %s of %s on %s expires in %s (%s)",
+			src.ClientID,
+			dst.ChainName,
+			src.ChainName,
+			src.ClientExpiry.Sub(now).Truncate(time.Minute),
+			src.ClientExpiry.UTC().Format(time.RFC3339),
+		)
+		alert.Labels = map[string]string{
+			"src_chain_id":   src.ChainID,
+			"dst_chain_id":   dst.ChainID,
+			"src_chain_name": src.ChainName,
+			"dst_chain_name": dst.ChainName,
+			"client_id":      src.ClientID,
+			"discord_ids":    strings.Join(ids, ","),
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+func stuckPacketsAlerts(rule *config.StuckPacketsRule, p collector.PathStatus, ids []string) []Alert {
+	alerts := []Alert{}
+
+	for _, c := range p.Channels {
+		ends := []struct {
+			src, dst         collector.ChainStatus
+			srcChan, dstChan string
+			stuck            int
+		}{
+			{p.Chain1, p.Chain2, c.SrcChannelID, c.DstChannelID, c.SrcStuckPackets},
+			{p.Chain2, p.Chain1, c.DstChannelID, c.SrcChannelID, c.DstStuckPackets},
+		}
+
+		for _, e := range ends {
+			if e.stuck <= rule.Count {
+				continue
+			}
+
+			alert := pathAlert(RuleStuckPackets, p, ids)
+			alert.Summary = fmt.Sprintf(
+				"%d stuck packets on %s %s -> %s %s",
+				e.stuck, e.src.ChainName, e.srcChan, e.dst.ChainName, e.dstChan,
+			)
+			alert.Labels = map[string]string{
+				"src_channel_id": e.srcChan,
+				"dst_channel_id": e.dstChan,
+				"src_chain_id":   e.src.ChainID,
+				"dst_chain_id":   e.dst.ChainID,
+				"src_chain_name": e.src.ChainName,
+				"dst_chain_name": e.dst.ChainName,
+				"discord_ids":    strings.Join(ids, ","),
+			}
+			alert.For = rule.For
+
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts
+}
+
+// walletBalanceAlerts returns alerts for accounts in rule denom below their
+// configured minimum balance or rule minimum if they have none, like
+// generated Prometheus rules.
+func walletBalanceAlerts(rule config.WalletBalanceRule, accounts []collector.AccountStatus) []Alert {
+	alerts := []Alert{}
+
+	for _, a := range accounts {
+		minBalance := rule.Min
+		if a.MinBalance != "" {
+			minBalance = a.MinBalance
+		}
+
+		// Minimums are validated as numeric and might be decimals.
+		threshold, err := math.LegacyNewDecFromStr(minBalance)
+		if err != nil || a.Denom != rule.Denom || a.Balance == nil ||
+			!math.LegacyNewDecFromInt(*a.Balance).LT(threshold) {
+			continue
+		}
+
+		alerts = append(alerts, Alert{
+			Rule: RuleWalletBalance,
+			Summary: fmt.Sprintf(
+				"Balance of %s on %s is %s%s, below %s%s",
+				a.Address, a.ChainName, a.Balance, a.Denom, minBalance, a.Denom,
+			),
+			Labels: map[string]string{
+				"account":  a.Address,
+				"chain_id": a.ChainID,
+				"denom":    a.Denom,
+				"tags":     strings.Join(a.Tags, ","),
+			},
+			DiscordIDs: []string{},
+			Chains:     []string{a.ChainName},
+			Operators:  []string{},
+			Tags:       a.Tags,
+		})
+	}
+
+	return alerts
+}
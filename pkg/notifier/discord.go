@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type discordAllowedMentions struct {
+	Users []string `json:"users"`
+}
+
+type discordMessage struct {
+	Content         string                 `json:"content"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+// Discord sends notifications to a Discord webhook mentioning operators.
+type Discord struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (d *Discord) Send(ctx context.Context, n Notification) error {
+	state := "FIRING"
+	if n.Resolved {
+		state = "RESOLVED"
+	}
+
+	content := fmt.Sprintf("**[%s]** %s", state, n.Alert.Summary)
+
+	mentions := make([]string, 0, len(n.Alert.DiscordIDs))
+	for _, id := range n.Alert.DiscordIDs {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+	}
+
+	if len(mentions) > 0 {
+		content += "\n" + strings.Join(mentions, " ")
+	}
+
+	return postJSON(ctx, d.Client, d.WebhookURL, discordMessage{
+		Content:         content,
+		AllowedMentions: discordAllowedMentions{Users: n.Alert.DiscordIDs},
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from webhook", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const httpTimeout = 10 * time.Second
+
+// Notification is an alert state change or repeat sent to a Sender.
+type Notification struct {
+	Alert    Alert     `json:"alert"`
+	Resolved bool      `json:"resolved"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+// Sender delivers notifications to an external service.
+type Sender interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+type alertState struct {
+	alert       Alert
+	activeSince time.Time
+	// notifiedAt holds time of the last notification per receiver.
+	notifiedAt map[string]time.Time
+}
+
+// Notifier evaluates notification rules against collected data and sends
+// notifications for firing and resolved alerts to receivers alerts are
+// routed to. Firing alerts are de-duplicated and repeated after repeat
+// interval.
+type Notifier struct {
+	cfg      *config.Notifications
+	store    *collector.StatusStore
+	gatherer prometheus.Gatherer
+	senders  map[string]Sender
+	states   map[string]*alertState
+	now      func() time.Time
+}
+
+// New returns notifier evaluating data in store. If gatherer is not nil it
+// is gathered before each evaluation so collectors refresh the store even
+// when the exporter is not scraped. senders are receivers by name.
+func New(
+	cfg *config.Notifications,
+	store *collector.StatusStore,
+	gatherer prometheus.Gatherer,
+	senders map[string]Sender,
+) *Notifier {
+	return &Notifier{
+		cfg:      cfg,
+		store:    store,
+		gatherer: gatherer,
+		senders:  senders,
+		states:   map[string]*alertState{},
+		now:      time.Now,
+	}
+}
+
+// Run evaluates rules every configured interval until ctx is done.
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.GetInterval())
+	defer ticker.Stop()
+
+	for {
+		if n.gatherer != nil {
+			if _, err := n.gatherer.Gather(); err != nil {
+				log.Error(err.Error())
+			}
+		}
+
+		n.Evaluate(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping notifier")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate runs a single round of rules evaluation and notifications.
+func (n *Notifier) Evaluate(ctx context.Context) {
+	now := n.now()
+	active := map[string]bool{}
+
+	for _, alert := range evaluate(n.cfg.Rules, n.store.Paths(), n.store.Accounts(), now) {
+		key := alert.Key()
+		active[key] = true
+
+		state, ok := n.states[key]
+		if !ok {
+			state = &alertState{activeSince: now, notifiedAt: map[string]time.Time{}}
+			n.states[key] = state
+		}
+
+		state.alert = alert
+
+		if now.Sub(state.activeSince) < alert.For {
+			continue
+		}
+
+		for _, receiver := range receivers(n.cfg, alert) {
+			last, notified := state.notifiedAt[receiver]
+			if notified && now.Sub(last) < n.cfg.GetRepeatInterval() {
+				continue
+			}
+
+			if n.send(ctx, receiver, Notification{Alert: alert, StartsAt: state.activeSince}) {
+				state.notifiedAt[receiver] = now
+			}
+		}
+	}
+
+	for key, state := range n.states {
+		if active[key] {
+			continue
+		}
+
+		// Only receivers notified about the alert get resolve notification,
+		// alerts that never fired are dropped silently.
+		for receiver := range state.notifiedAt {
+			notification := Notification{Alert: state.alert, Resolved: true, StartsAt: state.activeSince}
+			if n.send(ctx, receiver, notification) {
+				delete(state.notifiedAt, receiver)
+			}
+		}
+
+		if len(state.notifiedAt) == 0 {
+			delete(n.states, key)
+		}
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, receiver string, notification Notification) bool {
+	sender, ok := n.senders[receiver]
+	if !ok {
+		log.Error("Unknown notification receiver", zap.String("receiver", receiver))
+		return false
+	}
+
+	if err := sender.Send(ctx, notification); err != nil {
+		log.Error(
+			"Failed to send notification",
+			zap.String("receiver", receiver),
+			zap.String("alert", notification.Alert.Key()),
+			zap.Error(err),
+		)
+
+		return false
+	}
+
+	return true
+}
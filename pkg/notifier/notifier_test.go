@@ -0,0 +1,375 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+type webhookStandIn struct {
+	mu       sync.Mutex
+	messages []discordMessage
+	server   *httptest.Server
+}
+
+func newWebhookStandIn(t *testing.T) *webhookStandIn {
+	w := &webhookStandIn{}
+	w.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		msg := discordMessage{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.messages = append(w.messages, msg)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(w.server.Close)
+
+	return w
+}
+
+func (w *webhookStandIn) pop() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	contents := []string{}
+	for _, m := range w.messages {
+		contents = append(contents, m.Content)
+	}
+
+	w.messages = nil
+
+	return contents
+}
+
+func discordSenders(url string) map[string]Sender {
+	return map[string]Sender{config.DiscordReceiver: &Discord{WebhookURL: url}}
+}
+
+func testPathStatus(expiry time.Time, stuck int) collector.PathStatus {
+	return collector.PathStatus{
+		Name: "archway-osmosis",
+		Chain1: collector.ChainStatus{
+			ChainName: "archway", ChainID: "archway-1", ClientID: "07-tendermint-1", ClientExpiry: &expiry,
+		},
+		Chain2: collector.ChainStatus{
+			ChainName: "osmosis", ChainID: "osmosis-1", ClientID: "07-tendermint-2", ClientExpiry: &expiry,
+		},
+		Channels: []collector.ChannelStatus{
+			{SrcChannelID: "channel-1", DstChannelID: "channel-5", SrcStuckPackets: stuck},
+		},
+		Operators: []config.Operator{
+			{Name: "Archway", Discord: config.Discord{ID: "400514913505640451"}},
+			{Name: "Invalid", Discord: config.Discord{ID: "archway#1234"}},
+		},
+	}
+}
+
+func TestNotifierEvaluate(t *testing.T) {
+	webhook := newWebhookStandIn(t)
+	start := time.Unix(1700000000, 0)
+	now := start
+	store := collector.NewStatusStore()
+
+	cfg := &config.Notifications{
+		RepeatInterval: time.Hour,
+		Discord:        &config.DiscordWebhook{},
+		Rules: &config.NotificationRules{
+			ClientExpiry: &config.ClientExpiryRule{Within: 72 * time.Hour},
+			StuckPackets: &config.StuckPacketsRule{Count: 0, For: 10 * time.Minute},
+		},
+	}
+
+	n := New(cfg, store, nil, discordSenders(webhook.server.URL))
+	n.now = func() time.Time { return now }
+
+	// Far expiry with stuck packets, which must be stuck for 10m first.
+	store.UpdatePath(testPathStatus(start.Add(30*24*time.Hour), 3))
+	n.Evaluate(ctx)
+	assert.Empty(t, webhook.pop())
+
+	now = start.Add(11 * time.Minute)
+	n.Evaluate(ctx)
+	assert.Equal(t, []string{
+		"**[FIRING]** 3 stuck packets on archway channel-1 -> osmosis channel-5\n<@400514913505640451>",
+	}, webhook.pop())
+
+	// De-duplicated until repeat interval passes.
+	now = start.Add(30 * time.Minute)
+	n.Evaluate(ctx)
+	assert.Empty(t, webhook.pop())
+
+	now = start.Add(72 * time.Minute)
+	n.Evaluate(ctx)
+	assert.Len(t, webhook.pop(), 1)
+
+	// Stuck packets relayed and clients close to expiry.
+	store.UpdatePath(testPathStatus(now.Add(48*time.Hour), 0))
+	n.Evaluate(ctx)
+	assert.ElementsMatch(t, []string{
+		"**[FIRING]** Client 07-tendermint-1 of osmosis on archway expires in 48h0m0s (2023-11-16T23:25:20Z)" +
+			"\n<@400514913505640451>",
+		"**[FIRING]** Client 07-tendermint-2 of archway on osmosis expires in 48h0m0s (2023-11-16T23:25:20Z)" +
+			"\n<@400514913505640451>",
+		"**[RESOLVED]** 3 stuck packets on archway channel-1 -> osmosis channel-5\n<@400514913505640451>",
+	}, webhook.pop())
+}
+
+func TestNotifierDropsAlertsThatNeverFired(t *testing.T) {
+	webhook := newWebhookStandIn(t)
+	now := time.Unix(1700000000, 0)
+	store := collector.NewStatusStore()
+
+	cfg := &config.Notifications{
+		Discord: &config.DiscordWebhook{},
+		Rules: &config.NotificationRules{
+			StuckPackets: &config.StuckPacketsRule{Count: 0, For: 10 * time.Minute},
+		},
+	}
+
+	n := New(cfg, store, nil, discordSenders(webhook.server.URL))
+	n.now = func() time.Time { return now }
+
+	store.UpdatePath(testPathStatus(now.Add(30*24*time.Hour), 3))
+	n.Evaluate(ctx)
+
+	store.UpdatePath(testPathStatus(now.Add(30*24*time.Hour), 0))
+	n.Evaluate(ctx)
+
+	assert.Empty(t, webhook.pop())
+	assert.Empty(t, n.states)
+}
+
+func TestNotifierRetriesFailedNotifications(t *testing.T) {
+	var mu sync.Mutex
+
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if failing {
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	store := collector.NewStatusStore()
+	store.UpdatePath(testPathStatus(now.Add(time.Hour), 0))
+
+	cfg := &config.Notifications{
+		Discord: &config.DiscordWebhook{},
+		Rules:   &config.NotificationRules{ClientExpiry: &config.ClientExpiryRule{Within: 72 * time.Hour}},
+	}
+
+	n := New(cfg, store, nil, discordSenders(server.URL))
+	n.now = func() time.Time { return now }
+
+	n.Evaluate(ctx)
+
+	for _, s := range n.states {
+		assert.Empty(t, s.notifiedAt)
+	}
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	n.Evaluate(ctx)
+
+	for _, s := range n.states {
+		assert.Equal(t, map[string]time.Time{config.DiscordReceiver: now}, s.notifiedAt)
+	}
+}
+
+func TestClientExpiryAlerts(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	// Client on archway has a configured threshold taking precedence over
+	// the rule.
+	p := testPathStatus(now.Add(48*time.Hour), 0)
+	p.Chain1.ClientExpiryThreshold = 24 * time.Hour
+
+	alerts := clientExpiryAlerts(&config.ClientExpiryRule{Within: 72 * time.Hour}, p, nil, now)
+
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "07-tendermint-2", alerts[0].Labels["client_id"])
+}
+
+func TestWalletBalanceAlerts(t *testing.T) {
+	low := math.NewInt(10)
+	high := math.NewInt(1000)
+
+	accounts := []collector.AccountStatus{
+		{Address: "archway1low", ChainName: "archway", ChainID: "archway-1", Denom: "aarch", Balance: &low},
+		{Address: "archway1high", ChainName: "archway", ChainID: "archway-1", Denom: "aarch", Balance: &high},
+		{Address: "archway1unknown", ChainName: "archway", ChainID: "archway-1", Denom: "aarch"},
+		{Address: "osmo1low", ChainName: "osmosis", ChainID: "osmosis-1", Denom: "uosmo", Balance: &low},
+		// Configured minimums take precedence over the rule.
+		{Address: "archway1lowmin", ChainName: "archway", ChainID: "archway-1", Denom: "aarch", Balance: &low, MinBalance: "5"},
+		{Address: "archway1highmin", ChainName: "archway", ChainID: "archway-1", Denom: "aarch", Balance: &high, MinBalance: "2000"},
+	}
+
+	alerts := walletBalanceAlerts(config.WalletBalanceRule{Denom: "aarch", Min: "100"}, accounts)
+
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "archway1low", alerts[0].Labels["account"])
+	assert.Equal(t, "Balance of archway1low on archway is 10aarch, below 100aarch", alerts[0].Summary)
+	assert.Equal(t, "Balance of archway1highmin on archway is 1000aarch, below 2000aarch", alerts[1].Summary)
+
+	alerts = walletBalanceAlerts(config.WalletBalanceRule{Denom: "aarch", Min: "10.5"}, accounts)
+
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "Balance of archway1low on archway is 10aarch, below 10.5aarch", alerts[0].Summary)
+}
+
+var ctx = context.Background()
+
+func TestReceivers(t *testing.T) {
+	cfg := &config.Notifications{
+		Discord: &config.DiscordWebhook{},
+		Routes: []*config.Route{
+			{Receiver: "slack", Match: config.RouteMatch{Operators: []string{"Archway"}}},
+			{Receiver: "pagerduty", Match: config.RouteMatch{
+				Chains: []string{"archway"}, Paths: []string{"archway-noble"},
+			}},
+			{Receiver: "webhook", Match: config.RouteMatch{Tags: []string{"feegrant"}}},
+			{Receiver: "slack", Match: config.RouteMatch{Tags: []string{"feegrant"}}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		alert    Alert
+		expected []string
+	}{
+		{
+			name: "Path Operated By Archway",
+			alert: Alert{
+				Path: "archway-osmosis", Chains: []string{"archway", "osmosis"}, Operators: []string{"Archway"},
+			},
+			expected: []string{config.DiscordReceiver, "slack"},
+		},
+		{
+			name:     "Path Matching Chain And Path",
+			alert:    Alert{Path: "archway-noble", Chains: []string{"archway", "noble"}},
+			expected: []string{config.DiscordReceiver, "pagerduty"},
+		},
+		{
+			name:     "Path Matching Chain Only",
+			alert:    Alert{Path: "archway-juno", Chains: []string{"archway", "juno"}},
+			expected: []string{config.DiscordReceiver},
+		},
+		{
+			name:     "Account With Tag",
+			alert:    Alert{Chains: []string{"archway"}, Tags: []string{"feegrant"}},
+			expected: []string{config.DiscordReceiver, "webhook", "slack"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, receivers(cfg, tc.alert))
+		})
+	}
+}
+
+func TestSenders(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads = map[string]map[string]any{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		payload := map[string]any{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		payloads[r.URL.Path] = payload
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	senders := Senders(&config.Notifications{
+		Receivers: []*config.Receiver{
+			{Name: "slack", Slack: &config.SlackWebhook{WebhookURL: server.URL + "/slack"}},
+			{Name: "pagerduty", PagerDuty: &config.PagerDuty{RoutingKey: "key", URL: server.URL + "/pagerduty"}},
+			{Name: "webhook", Webhook: &config.Webhook{URL: server.URL + "/webhook"}},
+		},
+	})
+
+	alert := Alert{
+		Rule:    RuleClientExpiry,
+		Summary: "Client 07-tendermint-1 of osmosis on archway expires in 48h0m0s",
+		Labels:  map[string]string{"client_id": "07-tendermint-1", "src_chain_name": "archway"},
+		Path:    "archway-osmosis",
+	}
+
+	for _, name := range []string{"slack", "pagerduty", "webhook"} {
+		assert.NoError(t, senders[name].Send(ctx, Notification{Alert: alert}))
+	}
+
+	assert.NoError(t, senders["pagerduty"].Send(ctx, Notification{Alert: alert, Resolved: true}))
+
+	assert.Equal(t, "*[FIRING]* client_expiry", payloads["/slack"]["text"])
+	assert.Equal(t, []any{
+		map[string]any{
+			"color": "danger",
+			"text":  alert.Summary,
+			"fields": []any{
+				map[string]any{"title": "client_id", "value": "07-tendermint-1", "short": true},
+				map[string]any{"title": "src_chain_name", "value": "archway", "short": true},
+			},
+		},
+	}, payloads["/slack"]["attachments"])
+
+	assert.Equal(t, map[string]any{
+		"routing_key":  "key",
+		"event_action": "resolve",
+		"dedup_key":    alert.Key(),
+	}, payloads["/pagerduty"])
+
+	assert.Equal(t, false, payloads["/webhook"]["resolved"])
+	assert.Equal(t, alert.Summary, payloads["/webhook"]["alert"].(map[string]any)["summary"])
+	assert.Equal(t, map[string]any{"client_id": "07-tendermint-1", "src_chain_name": "archway"},
+		payloads["/webhook"]["alert"].(map[string]any)["labels"])
+}
+
+func TestPagerDutyTrigger(t *testing.T) {
+	event := pagerDutyEvent{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pd := &PagerDuty{RoutingKey: "key", URL: server.URL}
+	alert := Alert{Rule: RuleStuckPackets, Summary: "3 stuck packets", Path: "archway-osmosis"}
+
+	assert.NoError(t, pd.Send(ctx, Notification{Alert: alert}))
+	assert.Equal(t, "trigger", event.EventAction)
+	assert.Equal(t, &pagerDutyPayload{
+		Summary:   "3 stuck packets",
+		Source:    pagerDutySource,
+		Severity:  pagerDutyDefaultSeverity,
+		Component: "archway-osmosis",
+		Class:     RuleStuckPackets,
+	}, event.Payload)
+}
@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	pagerDutyEventsURL       = "https://events.pagerduty.com/v2/enqueue"
+	pagerDutyDefaultSeverity = "critical"
+	pagerDutySource          = "relayer_exporter"
+)
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// PagerDuty sends notifications as PagerDuty Events API v2 events. Alert key
+// is used as dedup key so resolve events close the triggered incident.
+type PagerDuty struct {
+	RoutingKey string
+	URL        string
+	Severity   string
+	Client     *http.Client
+}
+
+func (p *PagerDuty) Send(ctx context.Context, n Notification) error {
+	url := p.URL
+	if url == "" {
+		url = pagerDutyEventsURL
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    n.Alert.Key(),
+	}
+
+	if n.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		severity := p.Severity
+		if severity == "" {
+			severity = pagerDutyDefaultSeverity
+		}
+
+		event.Payload = &pagerDutyPayload{
+			Summary:       n.Alert.Summary,
+			Source:        pagerDutySource,
+			Severity:      severity,
+			Component:     n.Alert.Path,
+			Class:         n.Alert.Rule,
+			CustomDetails: n.Alert.Labels,
+		}
+	}
+
+	return postJSON(ctx, p.Client, url, event)
+}
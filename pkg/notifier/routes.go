@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"net/http"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// Senders returns senders of all receivers configured in cfg by receiver name.
+func Senders(cfg *config.Notifications) map[string]Sender {
+	senders := map[string]Sender{}
+	client := &http.Client{Timeout: httpTimeout}
+
+	if cfg.Discord != nil {
+		senders[config.DiscordReceiver] = &Discord{WebhookURL: cfg.Discord.WebhookURL, Client: client}
+	}
+
+	for _, r := range cfg.Receivers {
+		switch {
+		case r.Discord != nil:
+			senders[r.Name] = &Discord{WebhookURL: r.Discord.WebhookURL, Client: client}
+		case r.Slack != nil:
+			senders[r.Name] = &Slack{WebhookURL: r.Slack.WebhookURL, Client: client}
+		case r.PagerDuty != nil:
+			senders[r.Name] = &PagerDuty{
+				RoutingKey: r.PagerDuty.RoutingKey,
+				URL:        r.PagerDuty.URL,
+				Severity:   r.PagerDuty.Severity,
+				Client:     client,
+			}
+		case r.Webhook != nil:
+			senders[r.Name] = &Webhook{URL: r.Webhook.URL, Client: client}
+		}
+	}
+
+	return senders
+}
+
+// receivers returns names of receivers alert is routed to.
+func receivers(cfg *config.Notifications, alert Alert) []string {
+	names := []string{}
+	seen := map[string]bool{}
+
+	if cfg.Discord != nil {
+		names = append(names, config.DiscordReceiver)
+		seen[config.DiscordReceiver] = true
+	}
+
+	for _, r := range cfg.Routes {
+		if seen[r.Receiver] || !matches(r.Match, alert) {
+			continue
+		}
+
+		names = append(names, r.Receiver)
+		seen[r.Receiver] = true
+	}
+
+	return names
+}
+
+// matches returns true if alert matches any value of every non-empty field.
+func matches(m config.RouteMatch, alert Alert) bool {
+	return matchesAny(m.Chains, alert.Chains) &&
+		matchesAny(m.Paths, []string{alert.Path}) &&
+		matchesAny(m.Operators, alert.Operators) &&
+		matchesAny(m.Tags, alert.Tags)
+}
+
+func matchesAny(values, alertValues []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		for _, av := range alertValues {
+			if v == av {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text"`
+	Fields []slackField `json:"fields"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// Slack sends notifications to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s *Slack) Send(ctx context.Context, n Notification) error {
+	state, color := "FIRING", "danger"
+	if n.Resolved {
+		state, color = "RESOLVED", "good"
+	}
+
+	names := make([]string, 0, len(n.Alert.Labels))
+	for name := range n.Alert.Labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fields := make([]slackField, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, slackField{Title: name, Value: n.Alert.Labels[name], Short: true})
+	}
+
+	return postJSON(ctx, s.Client, s.WebhookURL, slackMessage{
+		Text: fmt.Sprintf("*[%s]* %s", state, n.Alert.Rule),
+		Attachments: []slackAttachment{
+			{Color: color, Text: n.Alert.Summary, Fields: fields},
+		},
+	})
+}
@@ -0,0 +1,16 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+)
+
+// Webhook posts notifications as JSON to a generic HTTP endpoint.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.Client, w.URL, n)
+}
@@ -0,0 +1,73 @@
+package remediation
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	ActionUpdateClient = "update_client"
+	ActionFlushPackets = "flush_packets"
+)
+
+// Entry is a single remediation recorded in audit log.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Path   string    `json:"path"`
+	// ChainID is the chain of the client or channel end remediated.
+	ChainID      string     `json:"chain_id"`
+	ClientID     string     `json:"client_id,omitempty"`
+	ClientExpiry *time.Time `json:"client_expiry,omitempty"`
+	ChannelID    string     `json:"channel_id,omitempty"`
+	// StuckSince is since when the channel has stuck packets.
+	StuckSince *time.Time `json:"stuck_since,omitempty"`
+	// Packets is how many packets were relayed, or would be in dry run.
+	Packets int    `json:"packets,omitempty"`
+	DryRun  bool   `json:"dry_run"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AuditLog appends entries to a writer as JSON lines.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// OpenAuditLog opens audit log file at path for appending, creating it if
+// needed.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditLog{w: f}, nil
+}
+
+// Record appends entry to log.
+func (a *AuditLog) Record(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, err = a.w.Write(append(data, '\n'))
+
+	return err
+}
+
+func (a *AuditLog) Close() error {
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package remediation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const packetsFlushedMetricName = "cosmos_ibc_packets_flushed_total"
+
+var packetsFlushed = prometheus.NewDesc(
+	packetsFlushedMetricName,
+	"Returns total packets relayed by stuck packets remediation for a channel.",
+	[]string{
+		"src_channel_id",
+		"dst_channel_id",
+		"src_chain_id",
+		"dst_chain_id",
+		"src_chain_name",
+		"dst_chain_name",
+	},
+	nil,
+)
+
+// Sender sends remediation transactions.
+type Sender interface {
+	// UpdateClient sends MsgUpdateClient updating client hosted on host with
+	// headers of counterparty and returns hash of the transaction.
+	UpdateClient(ctx context.Context, host, counterparty collector.ChainStatus) (string, error)
+	// FlushPackets relays at most limit unrelayed packets of channel and
+	// returns how many were relayed, also if it fails after relaying some.
+	FlushPackets(ctx context.Context, path collector.PathStatus, channel collector.ChannelStatus, limit int) (int, error)
+}
+
+type flushedKey struct {
+	srcChannelID, dstChannelID string
+	srcChainID, dstChainID     string
+	srcChainName, dstChainName string
+}
+
+// Remediator evaluates collected status of allowlisted paths and sends
+// transactions fixing them. Every remediation, sent or not, is recorded in
+// audit log. It is also a collector of remediation metrics.
+type Remediator struct {
+	cfg      *config.Remediation
+	store    *collector.StatusStore
+	history  *collector.PathHistory
+	gatherer prometheus.Gatherer
+	sender   Sender
+	audit    *AuditLog
+	now      func() time.Time
+
+	mu      sync.Mutex
+	flushed map[flushedKey]int
+}
+
+// New returns remediator evaluating data in store and history. If gatherer
+// is not nil it is gathered before each evaluation so collectors refresh
+// the store even when the exporter is not scraped.
+func New(
+	cfg *config.Remediation,
+	store *collector.StatusStore,
+	history *collector.PathHistory,
+	gatherer prometheus.Gatherer,
+	sender Sender,
+	audit *AuditLog,
+) *Remediator {
+	return &Remediator{
+		cfg:      cfg,
+		store:    store,
+		history:  history,
+		gatherer: gatherer,
+		sender:   sender,
+		audit:    audit,
+		now:      time.Now,
+		flushed:  map[flushedKey]int{},
+	}
+}
+
+func (r *Remediator) Describe(ch chan<- *prometheus.Desc) {
+	ch <- packetsFlushed
+}
+
+func (r *Remediator) Collect(ch chan<- prometheus.Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, count := range r.flushed {
+		ch <- prometheus.MustNewConstMetric(
+			packetsFlushed,
+			prometheus.CounterValue,
+			float64(count),
+			k.srcChannelID,
+			k.dstChannelID,
+			k.srcChainID,
+			k.dstChainID,
+			k.srcChainName,
+			k.dstChainName,
+		)
+	}
+}
+
+// Run evaluates paths every configured interval until ctx is done.
+func (r *Remediator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.GetInterval())
+	defer ticker.Stop()
+
+	for {
+		if r.gatherer != nil {
+			if _, err := r.gatherer.Gather(); err != nil {
+				log.Error(err.Error())
+			}
+		}
+
+		r.Evaluate(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping remediation")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate runs a single round of remediation.
+func (r *Remediator) Evaluate(ctx context.Context) {
+	paths := r.store.Paths()
+	sort.Slice(paths, func(i, j int) bool { return paths[i].Name < paths[j].Name })
+
+	budget := 0
+	if r.cfg.PacketFlush != nil {
+		budget = r.cfg.PacketFlush.GetMaxPackets()
+	}
+
+	for _, p := range paths {
+		if !r.cfg.Allows(p.Chain1.ChainName, p.Chain2.ChainName) {
+			continue
+		}
+
+		if r.cfg.ClientUpdate != nil {
+			r.updateClients(ctx, p)
+		}
+
+		if r.cfg.PacketFlush != nil {
+			budget = r.flushPackets(ctx, p, budget)
+		}
+	}
+}
+
+// updateClients updates clients of path expiring within threshold. Expired
+// clients can't be updated and are skipped.
+func (r *Remediator) updateClients(ctx context.Context, p collector.PathStatus) {
+	now := r.now()
+
+	for _, c := range [][2]collector.ChainStatus{
+		{p.Chain1, p.Chain2},
+		{p.Chain2, p.Chain1},
+	} {
+		host, counterparty := c[0], c[1]
+
+		if host.ClientExpiry == nil || host.ClientExpiry.Sub(now) > r.cfg.ClientUpdate.Threshold {
+			continue
+		}
+
+		if !host.ClientExpiry.After(now) {
+			log.Warn(
+				"Client expired, skipping update",
+				zap.String("chain_id", host.ChainID),
+				zap.String("client_id", host.ClientID),
+			)
+
+			continue
+		}
+
+		entry := Entry{
+			Time:         now,
+			Action:       ActionUpdateClient,
+			Path:         p.Name,
+			ChainID:      host.ChainID,
+			ClientID:     host.ClientID,
+			ClientExpiry: host.ClientExpiry,
+			DryRun:       r.cfg.DryRun,
+		}
+
+		if !r.cfg.DryRun {
+			hash, err := r.sender.UpdateClient(ctx, host, counterparty)
+			entry.TxHash = hash
+
+			if err != nil {
+				entry.Error = err.Error()
+			}
+		}
+
+		r.record(entry)
+	}
+}
+
+// flushPackets relays packets of path channels stuck for at least min age
+// until budget of packets is used up and returns the remaining budget.
+func (r *Remediator) flushPackets(ctx context.Context, p collector.PathStatus, budget int) int {
+	now := r.now()
+
+	for _, c := range p.Channels {
+		if budget <= 0 {
+			return 0
+		}
+
+		since := r.stuckSince(p, c)
+		if since == nil || now.Sub(*since) < r.cfg.PacketFlush.MinAge {
+			continue
+		}
+
+		entry := Entry{
+			Time:       now,
+			Action:     ActionFlushPackets,
+			Path:       p.Name,
+			ChainID:    p.Chain1.ChainID,
+			ChannelID:  c.SrcChannelID,
+			StuckSince: since,
+			DryRun:     r.cfg.DryRun,
+		}
+
+		if r.cfg.DryRun {
+			entry.Packets = min(c.SrcStuckPackets+c.DstStuckPackets, budget)
+		} else {
+			flushed, err := r.sender.FlushPackets(ctx, p, c, budget)
+			entry.Packets = flushed
+
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			r.addFlushed(p, c, flushed)
+		}
+
+		budget -= entry.Packets
+
+		r.record(entry)
+	}
+
+	return budget
+}
+
+// stuckSince returns since when either end of channel has stuck packets.
+func (r *Remediator) stuckSince(p collector.PathStatus, c collector.ChannelStatus) *time.Time {
+	var since *time.Time
+
+	if c.SrcStuckPackets > 0 {
+		since = r.history.StuckSince(collector.ChannelKey(p.Chain1.ChainName, c.SrcChannelID))
+	}
+
+	if c.DstStuckPackets > 0 {
+		dst := r.history.StuckSince(collector.ChannelKey(p.Chain2.ChainName, c.DstChannelID))
+		if since == nil || (dst != nil && dst.Before(*since)) {
+			since = dst
+		}
+	}
+
+	return since
+}
+
+// addFlushed counts packets flushed on channel.
+func (r *Remediator) addFlushed(p collector.PathStatus, c collector.ChannelStatus, flushed int) {
+	if flushed == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := flushedKey{
+		srcChannelID: c.SrcChannelID,
+		dstChannelID: c.DstChannelID,
+		srcChainID:   p.Chain1.ChainID,
+		dstChainID:   p.Chain2.ChainID,
+		srcChainName: p.Chain1.ChainName,
+		dstChainName: p.Chain2.ChainName,
+	}
+
+	r.flushed[key] += flushed
+}
+
+func (r *Remediator) record(entry Entry) {
+	fields := []zap.Field{
+		zap.String("action", entry.Action),
+		zap.String("path", entry.Path),
+		zap.String("chain_id", entry.ChainID),
+		zap.Bool("dry_run", entry.DryRun),
+		zap.String("tx_hash", entry.TxHash),
+		zap.Int("packets", entry.Packets),
+	}
+
+	if entry.Error != "" {
+		log.Error("Remediation failed: "+entry.Error, fields...)
+	} else {
+		log.Info("Remediation", fields...)
+	}
+
+	if err := r.audit.Record(entry); err != nil {
+		log.Error("Failed to write audit log", zap.Error(err))
+	}
+}
@@ -0,0 +1,427 @@
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+type fakeSender struct {
+	updated []string
+	// flushed holds channels and limits of flushes.
+	flushed []string
+	err     error
+}
+
+func (f *fakeSender) UpdateClient(_ context.Context, host, counterparty collector.ChainStatus) (string, error) {
+	f.updated = append(f.updated, host.ChainName+"/"+host.ClientID+"<-"+counterparty.ChainName)
+
+	return "HASH", f.err
+}
+
+func (f *fakeSender) FlushPackets(
+	_ context.Context, _ collector.PathStatus, channel collector.ChannelStatus, limit int,
+) (int, error) {
+	f.flushed = append(f.flushed, fmt.Sprintf("%s/%d", channel.SrcChannelID, limit))
+
+	return min(channel.SrcStuckPackets+channel.DstStuckPackets, limit), f.err
+}
+
+// fakeProvider is a chain at height with a client of counterparty at
+// clientHeight. It builds tendermint headers of itself and records messages
+// sent to it.
+type fakeProvider struct {
+	provider.ChainProvider
+
+	chainID      string
+	height       int64
+	clientHeight uint64
+	res          *provider.RelayerTxResponse
+	ok           bool
+	err          error
+
+	mu   sync.Mutex
+	sent []provider.RelayerMessage
+}
+
+type fakeIBCHeader uint64
+
+func (h fakeIBCHeader) Height() uint64                             { return uint64(h) }
+func (h fakeIBCHeader) ConsensusState() ibcexported.ConsensusState { return nil }
+func (h fakeIBCHeader) NextValidatorsHash() []byte                 { return nil }
+
+// fakeMsgUpdateClient is an update of client with header.
+type fakeMsgUpdateClient struct {
+	clientID string
+	header   *ibctm.Header
+}
+
+func (m fakeMsgUpdateClient) Type() string              { return "/ibc.core.client.v1.MsgUpdateClient" }
+func (m fakeMsgUpdateClient) MsgBytes() ([]byte, error) { return nil, nil }
+
+type fakePacketMsg string
+
+func (m fakePacketMsg) Type() string              { return string(m) }
+func (m fakePacketMsg) MsgBytes() ([]byte, error) { return nil, nil }
+
+func (p *fakeProvider) ChainId() string { return p.chainID }
+
+func (p *fakeProvider) QueryLatestHeight(_ context.Context) (int64, error) {
+	return p.height, nil
+}
+
+func (p *fakeProvider) QueryClientState(_ context.Context, _ int64, _ string) (ibcexported.ClientState, error) {
+	return &ibctm.ClientState{LatestHeight: clienttypes.NewHeight(1, p.clientHeight)}, nil
+}
+
+func (p *fakeProvider) QueryIBCHeader(_ context.Context, h int64) (provider.IBCHeader, error) {
+	return fakeIBCHeader(h), nil
+}
+
+func (p *fakeProvider) MsgUpdateClientHeader(
+	latest provider.IBCHeader, trustedHeight clienttypes.Height, _ provider.IBCHeader,
+) (ibcexported.ClientMessage, error) {
+	return &ibctm.Header{
+		SignedHeader:  &cmtproto.SignedHeader{Header: &cmtproto.Header{ChainID: p.chainID, Height: int64(latest.Height())}},
+		TrustedHeight: trustedHeight,
+	}, nil
+}
+
+func (p *fakeProvider) MsgUpdateClient(clientID string, header ibcexported.ClientMessage) (provider.RelayerMessage, error) {
+	return fakeMsgUpdateClient{clientID: clientID, header: header.(*ibctm.Header)}, nil
+}
+
+func (p *fakeProvider) SendMessage(
+	_ context.Context, msg provider.RelayerMessage, _ string,
+) (*provider.RelayerTxResponse, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sent = append(p.sent, msg)
+
+	return p.res, p.ok, p.err
+}
+
+func (p *fakeProvider) SendMessages(
+	_ context.Context, msgs []provider.RelayerMessage, _ string,
+) (*provider.RelayerTxResponse, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sent = append(p.sent, msgs...)
+
+	return p.res, p.ok, p.err
+}
+
+func auditEntries(t *testing.T, buf *bytes.Buffer) []Entry {
+	entries := []Entry{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		entry := Entry{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestUpdateClients(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	soon := now.Add(time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+	expired := now.Add(-time.Hour)
+
+	store := collector.NewStatusStore()
+	store.UpdatePath(collector.PathStatus{
+		Name:   "archway-osmosis",
+		Chain1: collector.ChainStatus{ChainName: "archway", ChainID: "archway-1", ClientID: "07-tendermint-1", ClientExpiry: &soon},
+		Chain2: collector.ChainStatus{ChainName: "osmosis", ChainID: "osmosis-1", ClientID: "07-tendermint-2", ClientExpiry: &later},
+	})
+	store.UpdatePath(collector.PathStatus{
+		Name:   "archway-noble",
+		Chain1: collector.ChainStatus{ChainName: "archway", ChainID: "archway-1", ClientID: "07-tendermint-3", ClientExpiry: &soon},
+		Chain2: collector.ChainStatus{ChainName: "noble", ChainID: "noble-1", ClientID: "07-tendermint-4", ClientExpiry: &soon},
+	})
+	store.UpdatePath(collector.PathStatus{
+		Name:   "archway-axelar",
+		Chain1: collector.ChainStatus{ChainName: "archway", ChainID: "archway-1", ClientID: "07-tendermint-5", ClientExpiry: &expired},
+		Chain2: collector.ChainStatus{ChainName: "axelar", ChainID: "axelar-1", ClientID: "07-tendermint-6"},
+	})
+
+	testCases := []struct {
+		name            string
+		dryRun          bool
+		err             error
+		expectedUpdated []string
+		expectedEntry   Entry
+	}{
+		{
+			name:            "Update",
+			expectedUpdated: []string{"archway/07-tendermint-1<-osmosis"},
+			expectedEntry: Entry{
+				Time: now, Action: ActionUpdateClient, Path: "archway-osmosis", ChainID: "archway-1",
+				ClientID: "07-tendermint-1", ClientExpiry: &soon, TxHash: "HASH",
+			},
+		},
+		{
+			name:   "Dry Run",
+			dryRun: true,
+			expectedEntry: Entry{
+				Time: now, Action: ActionUpdateClient, Path: "archway-osmosis", ChainID: "archway-1",
+				ClientID: "07-tendermint-1", ClientExpiry: &soon, DryRun: true,
+			},
+		},
+		{
+			name:            "Failed",
+			err:             errors.New("out of gas"),
+			expectedUpdated: []string{"archway/07-tendermint-1<-osmosis"},
+			expectedEntry: Entry{
+				Time: now, Action: ActionUpdateClient, Path: "archway-osmosis", ChainID: "archway-1",
+				ClientID: "07-tendermint-1", ClientExpiry: &soon, TxHash: "HASH", Error: "out of gas",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Remediation{
+				Paths:        []string{"osmosis-archway", "archway-axelar"},
+				DryRun:       tc.dryRun,
+				ClientUpdate: &config.ClientUpdate{Threshold: 48 * time.Hour},
+			}
+			sender := &fakeSender{err: tc.err}
+			buf := &bytes.Buffer{}
+
+			r := New(cfg, store, nil, nil, sender, &AuditLog{w: buf})
+			r.now = func() time.Time { return now }
+			r.Evaluate(context.Background())
+
+			assert.Equal(t, tc.expectedUpdated, sender.updated)
+			assert.Equal(t, []Entry{tc.expectedEntry}, auditEntries(t, buf))
+		})
+	}
+}
+
+func TestChainSenderUpdateClient(t *testing.T) {
+	host := collector.ChainStatus{ChainName: "archway", ClientID: "07-tendermint-1"}
+	counterparty := collector.ChainStatus{ChainName: "osmosis", ClientID: "07-tendermint-2"}
+
+	testCases := []struct {
+		name         string
+		res          *provider.RelayerTxResponse
+		ok           bool
+		err          error
+		expectedHash string
+		expectedErr  string
+	}{
+		{
+			name:         "Sent",
+			res:          &provider.RelayerTxResponse{TxHash: "HASH"},
+			ok:           true,
+			expectedHash: "HASH",
+		},
+		{
+			name:         "Failed On Chain",
+			res:          &provider.RelayerTxResponse{TxHash: "HASH", Code: 11},
+			expectedHash: "HASH",
+			expectedErr:  "transaction HASH failed",
+		},
+		{
+			name:        "Not Broadcast",
+			err:         errors.New("insufficient funds"),
+			expectedErr: "insufficient funds",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			providers := map[string]*fakeProvider{
+				"archway": {chainID: "archway-1", height: 200, clientHeight: 900, res: tc.res, ok: tc.ok, err: tc.err},
+				"osmosis": {chainID: "osmosis-1", height: 1000, clientHeight: 150},
+			}
+
+			sender := ChainSender{
+				prepChain: func(_ context.Context, chainName, clientID string) (*relayer.Chain, error) {
+					c := relayer.NewChain(zap.NewNop(), providers[chainName], false)
+
+					return c, c.SetPath(&relayer.PathEnd{ClientID: clientID})
+				},
+			}
+
+			hash, err := sender.UpdateClient(context.Background(), host, counterparty)
+			assert.Equal(t, tc.expectedHash, hash)
+
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+
+			// Client on host is updated to the latest counterparty height,
+			// trusting the height host client is at.
+			assert.Empty(t, providers["osmosis"].sent)
+			assert.Len(t, providers["archway"].sent, 1)
+
+			msg := providers["archway"].sent[0].(fakeMsgUpdateClient)
+			assert.Equal(t, "07-tendermint-1", msg.clientID)
+			assert.Equal(t, "osmosis-1", msg.header.Header.ChainID)
+			assert.Equal(t, int64(1000), msg.header.Header.Height)
+			assert.Equal(t, clienttypes.NewHeight(1, 900), msg.header.TrustedHeight)
+		})
+	}
+}
+
+func TestPacketCounter(t *testing.T) {
+	src := relayer.NewChain(zap.NewNop(), &fakeProvider{chainID: "archway-1", ok: true}, false)
+	dst := relayer.NewChain(zap.NewNop(), &fakeProvider{chainID: "osmosis-1", err: errors.New("out of gas")}, false)
+
+	recv := fakePacketMsg("/ibc.core.channel.v1.MsgRecvPacket")
+	timeout := fakePacketMsg("/ibc.core.channel.v1.MsgTimeout")
+
+	// Packets to src are sent in 2 batches, the first with client update.
+	msgs := &relayer.RelayMsgs{
+		Src:          []provider.RelayerMessage{fakePacketMsg(msgUpdateClientType), recv, recv, recv},
+		Dst:          []provider.RelayerMessage{fakePacketMsg(msgUpdateClientType), timeout},
+		MaxTxSize:    maxTxSize,
+		MaxMsgLength: 2,
+	}
+
+	counter := &packetCounter{}
+	result := msgs.Send(context.Background(), zap.NewNop(), counter.sender(src), counter.sender(dst), "")
+
+	assert.Equal(t, 2, result.SuccessfulSrcBatches)
+	assert.Equal(t, 3, counter.packets)
+	assert.EqualError(t, result.Error(), "out of gas")
+}
+
+func TestFlushPackets(t *testing.T) {
+	history, err := collector.NewPathHistory(nil)
+	assert.NoError(t, err)
+
+	// Stuck since now.
+	_, err = history.UpdateStuckPackets(collector.ChannelKey("archway", "channel-1"), 30)
+	assert.NoError(t, err)
+	_, err = history.UpdateStuckPackets(collector.ChannelKey("osmosis", "channel-20"), 40)
+	assert.NoError(t, err)
+	_, err = history.UpdateStuckPackets(collector.ChannelKey("archway", "channel-3"), 5)
+	assert.NoError(t, err)
+
+	store := collector.NewStatusStore()
+	store.UpdatePath(collector.PathStatus{
+		Name:   "archway-osmosis",
+		Chain1: collector.ChainStatus{ChainName: "archway", ChainID: "archway-1"},
+		Chain2: collector.ChainStatus{ChainName: "osmosis", ChainID: "osmosis-1"},
+		Channels: []collector.ChannelStatus{
+			{SrcChannelID: "channel-1", DstChannelID: "channel-10", SrcStuckPackets: 30},
+			{SrcChannelID: "channel-2", DstChannelID: "channel-20", DstStuckPackets: 40},
+		},
+	})
+	store.UpdatePath(collector.PathStatus{
+		Name:     "archway-noble",
+		Chain1:   collector.ChainStatus{ChainName: "archway", ChainID: "archway-1"},
+		Chain2:   collector.ChainStatus{ChainName: "noble", ChainID: "noble-1"},
+		Channels: []collector.ChannelStatus{{SrcChannelID: "channel-3", DstChannelID: "channel-0", SrcStuckPackets: 5}},
+	})
+
+	testCases := []struct {
+		name            string
+		dryRun          bool
+		minAge          time.Duration
+		expectedFlushed []string
+		expectedPackets []int
+		expectedTotal   map[string]float64
+	}{
+		{
+			name:            "Capped",
+			minAge:          time.Hour,
+			expectedFlushed: []string{"channel-1/50", "channel-2/20"},
+			expectedPackets: []int{30, 20},
+			expectedTotal:   map[string]float64{"channel-1": 30, "channel-2": 20},
+		},
+		{
+			name:            "Dry Run",
+			dryRun:          true,
+			minAge:          time.Hour,
+			expectedPackets: []int{30, 20},
+			expectedTotal:   map[string]float64{},
+		},
+		{
+			name:            "Not Stuck Long Enough",
+			minAge:          3 * time.Hour,
+			expectedPackets: []int{},
+			expectedTotal:   map[string]float64{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Remediation{
+				Paths:       []string{"archway-osmosis"},
+				DryRun:      tc.dryRun,
+				PacketFlush: &config.PacketFlush{MinAge: tc.minAge},
+			}
+			sender := &fakeSender{}
+			buf := &bytes.Buffer{}
+
+			r := New(cfg, store, history, nil, sender, &AuditLog{w: buf})
+			r.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+			r.Evaluate(context.Background())
+
+			assert.Equal(t, tc.expectedFlushed, sender.flushed)
+
+			packets := []int{}
+			for _, e := range auditEntries(t, buf) {
+				assert.Equal(t, ActionFlushPackets, e.Action)
+				assert.Equal(t, tc.dryRun, e.DryRun)
+
+				packets = append(packets, e.Packets)
+			}
+
+			assert.Equal(t, tc.expectedPackets, packets)
+
+			ch := make(chan prometheus.Metric, 10)
+			r.Collect(ch)
+			close(ch)
+
+			total := map[string]float64{}
+
+			for m := range ch {
+				metric := &dto.Metric{}
+				assert.NoError(t, m.Write(metric))
+
+				for _, l := range metric.GetLabel() {
+					if l.GetName() == "src_channel_id" {
+						total[l.GetValue()] = metric.GetCounter().GetValue()
+					}
+				}
+			}
+
+			assert.Equal(t, tc.expectedTotal, total)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/cosmos/relayer/v2/relayer"
+	"github.com/cosmos/relayer/v2/relayer/provider"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	"github.com/archway-network/relayer_exporter/pkg/ibc"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+// Limits of relay transactions, the same as rly defaults.
+const (
+	maxTxSize    = 2 * 1024 * 1024
+	maxMsgLength = 5
+)
+
+var msgUpdateClientType = sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{})
+
+// ChainSender broadcasts remediation transactions signed with keys of
+// chains from RPC config.
+type ChainSender struct {
+	RPCs    *map[string]config.RPC
+	Keyring *config.Keyring
+	Memo    string
+
+	prepChain prepChainFunc
+}
+
+// prepChainFunc returns chain signing with key of chainName, with path end
+// of clientID.
+type prepChainFunc func(ctx context.Context, chainName, clientID string) (*relayer.Chain, error)
+
+func (s ChainSender) chain(ctx context.Context, chainName, clientID string) (*relayer.Chain, error) {
+	if s.prepChain != nil {
+		return s.prepChain(ctx, chainName, clientID)
+	}
+
+	return prepSigningChain(ctx, s.RPCs, s.Keyring, chainName, clientID)
+}
+
+// UpdateClient updates client of host chain with headers of counterparty
+// chain.
+func (s ChainSender) UpdateClient(ctx context.Context, host, counterparty collector.ChainStatus) (string, error) {
+	hostChain, err := s.chain(ctx, host.ChainName, host.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	counterpartyChain, err := s.chain(ctx, counterparty.ChainName, counterparty.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	hostHeight, counterpartyHeight, err := relayer.QueryLatestHeights(ctx, hostChain, counterpartyChain)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := relayer.MsgUpdateClient(ctx, counterpartyChain, hostChain, counterpartyHeight, hostHeight)
+	if err != nil {
+		return "", err
+	}
+
+	return send(ctx, hostChain, msg, s.Memo)
+}
+
+// FlushPackets relays at most limit packets unrelayed in both directions of
+// channel using the relayer packet flush logic. Packets that timed out are
+// timed out on their source chain.
+func (s ChainSender) FlushPackets(
+	ctx context.Context, path collector.PathStatus, channel collector.ChannelStatus, limit int,
+) (int, error) {
+	src, err := s.chain(ctx, path.Chain1.ChainName, path.Chain1.ClientID)
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := s.chain(ctx, path.Chain2.ChainName, path.Chain2.ClientID)
+	if err != nil {
+		return 0, err
+	}
+
+	ch := ibc.Channel{
+		Source:          channel.SrcChannelID,
+		Destination:     channel.DstChannelID,
+		SourcePort:      channel.SrcPortID,
+		DestinationPort: channel.DstPortID,
+		Ordering:        channel.Ordering,
+	}.Identified()
+
+	sequences := relayer.UnrelayedSequences(ctx, src, dst, ch)
+
+	// Sequences are in ascending order, the oldest packets are relayed first.
+	if len(sequences.Src) > limit {
+		sequences.Src = sequences.Src[:limit]
+	}
+
+	if len(sequences.Dst) > limit-len(sequences.Src) {
+		sequences.Dst = sequences.Dst[:limit-len(sequences.Src)]
+	}
+
+	if sequences.Empty() {
+		return 0, nil
+	}
+
+	return relayPackets(ctx, src, dst, sequences, ch, s.Memo)
+}
+
+// relayPackets relays sequences like relayer.RelayPackets and returns how
+// many packets were relayed or timed out. Unlike relayer.RelayPackets it
+// counts packets of successful transactions also on partial success.
+func relayPackets(
+	ctx context.Context, src, dst *relayer.Chain, sequences relayer.RelaySequences,
+	ch *chantypes.IdentifiedChannel, memo string,
+) (int, error) {
+	srch, dsth, err := relayer.QueryLatestHeights(ctx, src, dst)
+	if err != nil {
+		return 0, err
+	}
+
+	var msgsSrc1, msgsDst1, msgsSrc2, msgsDst2 []provider.RelayerMessage
+
+	eg, egCtx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		return relayer.AddMessagesForSequences(
+			egCtx, sequences.Src, src, dst, srch, dsth, &msgsSrc1, &msgsDst1,
+			ch.ChannelId, ch.PortId, ch.Counterparty.ChannelId, ch.Counterparty.PortId, ch.Ordering,
+		)
+	})
+
+	eg.Go(func() error {
+		return relayer.AddMessagesForSequences(
+			egCtx, sequences.Dst, dst, src, dsth, srch, &msgsDst2, &msgsSrc2,
+			ch.Counterparty.ChannelId, ch.Counterparty.PortId, ch.ChannelId, ch.PortId, ch.Ordering,
+		)
+	})
+
+	if err := eg.Wait(); err != nil {
+		return 0, err
+	}
+
+	msgs := &relayer.RelayMsgs{
+		Src:          append(msgsSrc1, msgsSrc2...),
+		Dst:          append(msgsDst1, msgsDst2...),
+		MaxTxSize:    maxTxSize,
+		MaxMsgLength: maxMsgLength,
+	}
+
+	// Packets might have been relayed since sequences were queried.
+	if !msgs.Ready() {
+		return 0, nil
+	}
+
+	if err := msgs.PrependMsgUpdateClient(ctx, src, dst, srch, dsth); err != nil {
+		return 0, err
+	}
+
+	counter := &packetCounter{}
+	result := msgs.Send(ctx, log.GetLogger(), counter.sender(src), counter.sender(dst), memo)
+
+	return counter.packets, result.Error()
+}
+
+// packetCounter counts packet messages in transactions which succeeded,
+// client updates prepended to them are not counted.
+type packetCounter struct {
+	mu      sync.Mutex
+	packets int
+}
+
+func (pc *packetCounter) sender(c *relayer.Chain) relayer.RelayMsgSender {
+	sender := relayer.AsRelayMsgSender(c)
+	send := sender.SendMessages
+
+	sender.SendMessages = func(
+		ctx context.Context, msgs []provider.RelayerMessage, memo string,
+	) (*provider.RelayerTxResponse, bool, error) {
+		res, ok, err := send(ctx, msgs, memo)
+		if ok {
+			pc.add(msgs)
+		}
+
+		return res, ok, err
+	}
+
+	return sender
+}
+
+func (pc *packetCounter) add(msgs []provider.RelayerMessage) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	for _, msg := range msgs {
+		if msg.Type() != msgUpdateClientType {
+			pc.packets++
+		}
+	}
+}
+
+// prepSigningChain returns chain with signer from RPC config of chainName.
+func prepSigningChain(
+	ctx context.Context, rpcs *map[string]config.RPC, keyring *config.Keyring, chainName, clientID string,
+) (*relayer.Chain, error) {
+	rpc, ok := (*rpcs)[chainName]
+	if !ok {
+		return nil, fmt.Errorf(config.ErrMissingRPCConfigMsg, chainName)
+	}
+
+	backend, home := "", ""
+
+	if keyring != nil {
+		backend, home = keyring.Backend, keyring.Home
+	}
+
+	return chain.PrepChain(ctx, chain.Info{
+		ChainID:  rpc.ChainID,
+		RPCAddr:  rpc.URL,
+		Timeout:  rpc.Timeout,
+		ClientID: clientID,
+		Signer: &chain.Signer{
+			Key:            rpc.Key,
+			KeyringBackend: backend,
+			Home:           home,
+			AccountPrefix:  rpc.AccountPrefix,
+			GasPrices:      rpc.GasPrices,
+			GasAdjustment:  rpc.GasAdjustment,
+		},
+	})
+}
+
+// send broadcasts msg and returns hash of the transaction, with error if
+// it failed on chain.
+func send(ctx context.Context, c *relayer.Chain, msg provider.RelayerMessage, memo string) (string, error) {
+	res, ok, err := c.ChainProvider.SendMessage(ctx, msg, memo)
+
+	hash := ""
+
+	if res != nil {
+		hash = res.TxHash
+	}
+
+	if err == nil && !ok {
+		err = fmt.Errorf("transaction %s failed", hash)
+	}
+
+	return hash, err
+}
@@ -0,0 +1,358 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+const (
+	FormatRules          = "rules"
+	FormatPrometheusRule = "prometheusrule"
+
+	groupName = "relayer_exporter"
+
+	// Thresholds exported for clients and accounts which configure them take
+	// precedence over thresholds of alert rules config.
+	clientExpiryThresholdMetric  = "cosmos_ibc_client_expiry_threshold_seconds"
+	walletBalanceThresholdMetric = "cosmos_wallet_balance_min_threshold"
+
+	// discordMentions renders comma separated discord_ids label as Discord
+	// mentions.
+	discordMentions = `{{ reReplaceAll "([0-9]+)" "<@$1>" $labels.discord_ids }}`
+)
+
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type Group struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// File is a Prometheus rules file.
+type File struct {
+	Groups []Group `yaml:"groups"`
+}
+
+type ObjectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// PrometheusRule is the prometheus-operator custom resource wrapping rule
+// groups.
+type PrometheusRule struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   ObjectMeta `yaml:"metadata"`
+	Spec       File       `yaml:"spec"`
+}
+
+// Generate returns alerting rules for thresholds in cfg. Expressions ignore
+// the status label, so alerts hold with both error policies, and failed
+// collections are alerted on separately by stale rules.
+func Generate(cfg *config.AlertRules) File {
+	rules := []Rule{}
+
+	if cfg.ClientExpiry != nil {
+		rules = append(rules, clientExpiryRules(cfg.ClientExpiry)...)
+		rules = append(rules, staleRule(
+			"IBCClientExpiryStale",
+			"cosmos_ibc_client_expiry_last_success_timestamp",
+			"Expiry of client {{ $labels.client_id }} on {{ $labels.src_chain_name }}",
+			cfg.GetStaleAfter(),
+			true,
+		))
+	}
+
+	if cfg.StuckPackets != nil {
+		rules = append(rules, stuckPacketsRules(cfg.StuckPackets)...)
+		rules = append(rules, staleRule(
+			"IBCStuckPacketsStale",
+			"cosmos_ibc_stuck_packets_last_success_timestamp",
+			"Stuck packets of {{ $labels.src_chain_name }} {{ $labels.src_channel_id }}",
+			cfg.GetStaleAfter(),
+			true,
+		))
+	}
+
+	if len(cfg.WalletBalance) > 0 {
+		for _, wb := range cfg.WalletBalance {
+			rules = append(rules, walletBalanceRules(wb)...)
+		}
+
+		rules = append(rules, staleRule(
+			"WalletBalanceStale",
+			"cosmos_wallet_balance_last_success_timestamp",
+			"Balance of {{ $labels.account }} on {{ $labels.chain_id }}",
+			cfg.GetStaleAfter(),
+			false,
+		))
+	}
+
+	for i := range rules {
+		rules[i].Labels = withLabels(rules[i].Labels, cfg.Labels)
+	}
+
+	return File{Groups: []Group{{Name: groupName, Rules: rules}}}
+}
+
+// clientExpiryRules returns a rule comparing clients with their exported
+// thresholds followed by rules of configured durations for clients without
+// thresholds.
+func clientExpiryRules(cfg *config.ClientExpiryAlert) []Rule {
+	rules := []Rule{clientExpiryThresholdRule(cfg)}
+	chains := sortedKeys(cfg.Chains)
+
+	for _, chain := range chains {
+		rules = append(rules, clientExpiryRule(cfg, eq("src_chain_name", chain), cfg.Chains[chain]))
+	}
+
+	return append(rules, clientExpiryRule(cfg, notIn("src_chain_name", chains), cfg.Within))
+}
+
+func clientExpiryThresholdRule(cfg *config.ClientExpiryAlert) Rule {
+	return Rule{
+		Alert: "IBCClientExpiring",
+		Expr: "max without (status) (cosmos_ibc_client_expiry) - time() < " +
+			clientExpiryThresholdMetric,
+		For: duration(cfg.For),
+		Annotations: map[string]string{
+			"summary": "Client {{ $labels.client_id }} of {{ $labels.dst_chain_name }} on " +
+				"{{ $labels.src_chain_name }} expires in {{ $value | humanizeDuration }}",
+			"description": "Light client expires within its configured threshold. " +
+				"Update the client before it expires.",
+			"discord_mentions": discordMentions,
+		},
+	}
+}
+
+func clientExpiryRule(cfg *config.ClientExpiryAlert, selector string, within time.Duration) Rule {
+	return Rule{
+		Alert: "IBCClientExpiring",
+		Expr: fmt.Sprintf(
+			"max without (status) (cosmos_ibc_client_expiry%s) - time() < %s unless %s",
+			selector, seconds(within), clientExpiryThresholdMetric,
+		),
+		For: duration(cfg.For),
+		Annotations: map[string]string{
+			"summary": "Client {{ $labels.client_id }} of {{ $labels.dst_chain_name }} on " +
+				"{{ $labels.src_chain_name }} expires in {{ $value | humanizeDuration }}",
+			"description": fmt.Sprintf(
+				"Light client expires within %s. Update the client before it expires.", duration(within),
+			),
+			"discord_mentions": discordMentions,
+		},
+	}
+}
+
+func stuckPacketsRules(cfg *config.StuckPacketsAlert) []Rule {
+	rules := []Rule{}
+	chains := sortedKeys(cfg.Chains)
+
+	for _, chain := range chains {
+		rules = append(rules, stuckPacketsRule(cfg, eq("src_chain_name", chain), cfg.Chains[chain]))
+	}
+
+	return append(rules, stuckPacketsRule(cfg, notIn("src_chain_name", chains), cfg.Count))
+}
+
+func stuckPacketsRule(cfg *config.StuckPacketsAlert, selector string, count int) Rule {
+	return Rule{
+		Alert: "IBCStuckPackets",
+		Expr: fmt.Sprintf(
+			"sum without (state) (max without (status) (cosmos_ibc_stuck_packets%s)) > %d", selector, count,
+		),
+		For: duration(cfg.For),
+		Annotations: map[string]string{
+			"summary": "{{ $value }} stuck packets on {{ $labels.src_chain_name }} {{ $labels.src_channel_id }} -> " +
+				"{{ $labels.dst_chain_name }} {{ $labels.dst_channel_id }}",
+			"description":      fmt.Sprintf("Channel has more than %d stuck packets. Check relayers of the path.", count),
+			"discord_mentions": discordMentions,
+		},
+	}
+}
+
+// walletBalanceRules returns a rule comparing accounts with their exported
+// minimum balances, then a rule for each tag override followed by the
+// default one. Each rule excludes accounts matched by the previous ones.
+func walletBalanceRules(cfg config.WalletBalanceAlert) []Rule {
+	rules := []Rule{walletBalanceThresholdRule(cfg)}
+	matchers := []string{eqMatcher("denom", cfg.Denom)}
+
+	for _, t := range cfg.Tags {
+		selector := "{" + strings.Join(append(matchers, tagMatcher("=~", t.Tag)), ",") + "}"
+		rules = append(rules, walletBalanceRule(cfg, selector, t.Min))
+		matchers = append(matchers, tagMatcher("!~", t.Tag))
+	}
+
+	selector := "{" + strings.Join(matchers, ",") + "}"
+
+	return append(rules, walletBalanceRule(cfg, selector, cfg.Min))
+}
+
+func walletBalanceThresholdRule(cfg config.WalletBalanceAlert) Rule {
+	return Rule{
+		Alert: "WalletBalanceLow",
+		Expr: fmt.Sprintf(
+			"max without (status) (cosmos_wallet_balance%s) < %s", eq("denom", cfg.Denom), walletBalanceThresholdMetric,
+		),
+		For: duration(cfg.For),
+		Annotations: map[string]string{
+			"summary": "Balance of {{ $labels.account }} on {{ $labels.chain_id }} is {{ $value }}{{ $labels.denom }}, " +
+				"below its configured minimum",
+			"description": "Relayer account balance is low. Top up the account.",
+		},
+	}
+}
+
+func walletBalanceRule(cfg config.WalletBalanceAlert, selector, minBalance string) Rule {
+	return Rule{
+		Alert: "WalletBalanceLow",
+		Expr: fmt.Sprintf(
+			"max without (status) (cosmos_wallet_balance%s) < %s unless %s",
+			selector, minBalance, walletBalanceThresholdMetric,
+		),
+		For: duration(cfg.For),
+		Annotations: map[string]string{
+			"summary": fmt.Sprintf(
+				"Balance of {{ $labels.account }} on {{ $labels.chain_id }} is {{ $value }}{{ $labels.denom }}, "+
+					"below %s{{ $labels.denom }}",
+				minBalance,
+			),
+			"description": "Relayer account balance is low. Top up the account.",
+		},
+	}
+}
+
+func staleRule(name, metric, subject string, staleAfter time.Duration, mentions bool) Rule {
+	rule := Rule{
+		Alert: name,
+		Expr:  fmt.Sprintf("time() - %s > %s", metric, seconds(staleAfter)),
+		Annotations: map[string]string{
+			"summary": subject + " not collected successfully for {{ $value | humanizeDuration }}",
+			"description": fmt.Sprintf(
+				"Queries have been failing for more than %s. Check RPC endpoints.", duration(staleAfter),
+			),
+		},
+	}
+
+	if mentions {
+		rule.Annotations["discord_mentions"] = discordMentions
+	}
+
+	return rule
+}
+
+// Write writes rules in format, either plain rules file or PrometheusRule
+// resource described by meta.
+func Write(w io.Writer, file File, format string, meta ObjectMeta) error {
+	var doc any
+
+	switch format {
+	case FormatRules:
+		doc = file
+	case FormatPrometheusRule:
+		doc = PrometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   meta,
+			Spec:       file,
+		}
+	default:
+		return fmt.Errorf("unknown rules format: %s", format)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func withLabels(labels, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return labels
+	}
+
+	res := map[string]string{}
+	for k, v := range extra {
+		res[k] = v
+	}
+
+	for k, v := range labels {
+		res[k] = v
+	}
+
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func eqMatcher(label, value string) string {
+	return label + "=" + strconv.Quote(value)
+}
+
+func eq(label, value string) string {
+	return "{" + eqMatcher(label, value) + "}"
+}
+
+// notIn returns selector excluding all values or empty selector if there
+// are none.
+func notIn(label string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, regexp.QuoteMeta(v))
+	}
+
+	return "{" + label + "!~" + strconv.Quote(strings.Join(quoted, "|")) + "}"
+}
+
+// tagMatcher matches tag in comma separated tags label.
+func tagMatcher(op, tag string) string {
+	return "tags" + op + strconv.Quote("(.*,)?"+regexp.QuoteMeta(tag)+"(,.*)?")
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+func duration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	return model.Duration(d).String()
+}
@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestWrite(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		format string
+		meta   ObjectMeta
+		golden string
+	}{
+		{
+			name:   "Rules",
+			config: "alert_rules.yaml",
+			format: FormatRules,
+			golden: "rules.golden.yaml",
+		},
+		{
+			name:   "Rules Without Overrides",
+			config: "alert_rules_minimal.yaml",
+			format: FormatRules,
+			golden: "rules_minimal.golden.yaml",
+		},
+		{
+			name:   "PrometheusRule",
+			config: "alert_rules.yaml",
+			format: FormatPrometheusRule,
+			meta:   ObjectMeta{Name: "relayer-exporter", Namespace: "monitoring"},
+			golden: "prometheusrule.golden.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tc.config))
+			assert.NoError(t, err)
+
+			cfg := &config.AlertRules{}
+			assert.NoError(t, yaml.Unmarshal(data, cfg))
+
+			buf := &bytes.Buffer{}
+			assert.NoError(t, Write(buf, Generate(cfg), tc.format, tc.meta))
+
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o600))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
+
+func TestGenerateParses(t *testing.T) {
+	for _, file := range []string{"alert_rules.yaml", "alert_rules_minimal.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", file))
+			assert.NoError(t, err)
+
+			cfg := &config.AlertRules{}
+			assert.NoError(t, yaml.Unmarshal(data, cfg))
+
+			for _, rule := range Generate(cfg).Groups[0].Rules {
+				_, err := parser.ParseExpr(rule.Expr)
+				assert.NoError(t, err, rule.Alert)
+
+				if rule.For != "" {
+					_, err := model.ParseDuration(rule.For)
+					assert.NoError(t, err, rule.Alert)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	assert.Error(t, Write(&bytes.Buffer{}, File{}, "json", ObjectMeta{}))
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	sortByName   = "name"
+	sortByExpiry = "expiry"
+	sortByStuck  = "stuck"
+
+	packetsPathParam    = "path"
+	packetsChannelParam = "channel"
+)
+
+// pathsStatus returns the latest collected status of every monitored path.
+// Paths not collected yet are returned without client and channel data.
+func pathsStatus(targets *Targets, store *collector.StatusStore) []collector.PathStatus {
+	rpcs := targets.RPCs()
+	paths := targets.Paths()
+	statuses := make([]collector.PathStatus, 0, len(paths))
+
+	for _, p := range paths {
+		status, ok := store.Path(p.Name())
+		if !ok {
+			status = collector.NewPathStatus(p, rpcs)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// sortPaths sorts statuses in place by name, time to expiry or stuck
+// packets. Paths with unknown expiry are sorted last by expiry.
+func sortPaths(statuses []collector.PathStatus, by string) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		switch by {
+		case sortByExpiry:
+			a, b := statuses[i].MinClientExpiry(), statuses[j].MinClientExpiry()
+			if a == nil || b == nil {
+				return a != nil
+			}
+
+			return a.Before(*b)
+		case sortByStuck:
+			return statuses[i].StuckPackets() > statuses[j].StuckPackets()
+		default:
+			return statuses[i].Name < statuses[j].Name
+		}
+	})
+}
+
+// PathsHandler returns handler serving status of all monitored paths as JSON.
+// Sort order can be changed with ?sort=name|expiry|stuck.
+func PathsHandler(targets *Targets, store *collector.StatusStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := pathsStatus(targets, store)
+		sortPaths(statuses, r.URL.Query().Get("sort"))
+
+		writeJSON(w, http.StatusOK, statuses)
+	})
+}
+
+// PacketsHandler returns handler serving stuck packets of a path from the
+// last collection with their metadata as JSON. Packets can be limited to a
+// channel of either chain with ?channel=<channel_id>.
+func PacketsHandler(targets *Targets, store *collector.StatusStore, cache *collector.PacketCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pathName := r.URL.Query().Get(packetsPathParam)
+		if pathName == "" {
+			http.Error(w, fmt.Sprintf("'%s' parameter must be specified", packetsPathParam), http.StatusBadRequest)
+			return
+		}
+
+		path := targets.Path(pathName)
+		if path == nil {
+			http.Error(w, fmt.Sprintf("unknown path %q", pathName), http.StatusNotFound)
+			return
+		}
+
+		packets := []collector.ChannelPackets{}
+
+		if status, ok := store.Path(path.Name()); ok {
+			packets = cache.StuckPackets(r.Context(), targets.RPCs(), status, r.URL.Query().Get(packetsChannelParam))
+		}
+
+		writeJSON(w, http.StatusOK, packets)
+	})
+}
+
+// RoutesHandler returns handler serving aggregated status of configured
+// packet forward routes with status of each hop as JSON.
+func RoutesHandler(targets *Targets, store *collector.StatusStore, history *collector.PathHistory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		paths := targets.Paths()
+		routes := targets.Routes()
+		statuses := make([]collector.RouteStatus, 0, len(routes))
+		now := time.Now()
+
+		for _, r := range routes {
+			statuses = append(statuses, collector.GetRouteStatus(r, paths, store, history, now))
+		}
+
+		writeJSON(w, http.StatusOK, statuses)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error(err.Error())
+	}
+}
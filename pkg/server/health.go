@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/chain"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+type check struct {
+	OK          bool       `json:"ok"`
+	Error       string     `json:"error,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+type chainsCheck struct {
+	OK                  bool              `json:"ok"`
+	Checked             bool              `json:"checked"`
+	Reachable           int               `json:"reachable"`
+	Total               int               `json:"total"`
+	MinReachablePercent float64           `json:"min_reachable_percent"`
+	Unreachable         map[string]string `json:"unreachable,omitempty"`
+}
+
+type readiness struct {
+	Ready    bool        `json:"ready"`
+	Registry check       `json:"registry"`
+	Chains   chainsCheck `json:"chains"`
+}
+
+// Health tracks state the exporter readiness depends on: IBC registry fetch
+// and reachability of chain RPC endpoints.
+type Health struct {
+	mu                  sync.RWMutex
+	minReachablePercent float64
+	registryErr         error
+	registrySuccess     *time.Time
+	chainsChecked       bool
+	chains              map[string]error
+}
+
+func NewHealth(minReachablePercent float64) *Health {
+	return &Health{
+		minReachablePercent: minReachablePercent,
+		chains:              map[string]error{},
+	}
+}
+
+func (h *Health) SetRegistry(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.registryErr = err
+
+	if err == nil {
+		now := time.Now()
+		h.registrySuccess = &now
+	}
+}
+
+// CheckChains queries every configured RPC endpoint concurrently and
+// records which chains are reachable.
+func (h *Health) CheckChains(ctx context.Context, rpcs *map[string]config.RPC) {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	chains := map[string]error{}
+
+	for name, rpc := range *rpcs {
+		wg.Add(1)
+
+		go func(name string, rpc config.RPC) {
+			defer wg.Done()
+
+			err := chain.CheckRPC(ctx, chain.Info{
+				ChainID: rpc.ChainID,
+				RPCAddr: rpc.URL,
+				Timeout: rpc.Timeout,
+			})
+			if err != nil {
+				log.Debug("Chain RPC unreachable", zap.String("chain", name), zap.Error(err))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			chains[name] = err
+		}(name, rpc)
+	}
+
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.chains = chains
+	h.chainsChecked = true
+}
+
+func (h *Health) readiness() readiness {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r := readiness{
+		// Readiness requires that the registry was fetched at least once,
+		// later failures are reported but keep the last fetched paths.
+		Registry: check{OK: h.registrySuccess != nil, LastSuccess: h.registrySuccess},
+		Chains: chainsCheck{
+			Checked:             h.chainsChecked,
+			Total:               len(h.chains),
+			MinReachablePercent: h.minReachablePercent,
+			Unreachable:         map[string]string{},
+		},
+	}
+
+	if h.registryErr != nil {
+		r.Registry.Error = h.registryErr.Error()
+	}
+
+	for name, err := range h.chains {
+		if err != nil {
+			r.Chains.Unreachable[name] = err.Error()
+			continue
+		}
+
+		r.Chains.Reachable++
+	}
+
+	r.Chains.OK = h.chainsChecked &&
+		float64(r.Chains.Reachable) >= float64(r.Chains.Total)*h.minReachablePercent/100
+
+	r.Ready = r.Registry.OK && r.Chains.OK
+
+	return r
+}
+
+// HealthzHandler returns handler reporting that the process is alive.
+func HealthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
+
+// ReadyzHandler returns handler reporting exporter readiness with details
+// of each check. It responds with 503 until the exporter is ready.
+func ReadyzHandler(h *Health) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r := h.readiness()
+
+		code := http.StatusOK
+		if !r.Ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, r)
+	})
+}
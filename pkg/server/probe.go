@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+const (
+	probePathParam    = "path"
+	probeAccountParam = "account"
+
+	// scrapeTimeoutHeader is set by Prometheus to the scrape timeout.
+	scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+	// scrapeTimeoutOffset leaves time to respond before Prometheus gives up.
+	scrapeTimeoutOffset = 500 * time.Millisecond
+)
+
+// ProbeHandler returns handler collecting metrics for a single IBC path
+// and/or account into a fresh registry, following the multi-target exporter
+// pattern, e.g. /probe?path=archway-osmosis or /probe?account=archway1...
+// Probes keep their own state, so they don't affect /metrics and the status
+// API, and their queries are canceled once the scrape times out.
+func ProbeHandler(targets *Targets, policies config.ErrorPolicies, denoms *collector.DenomCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pathName := r.URL.Query().Get(probePathParam)
+		address := r.URL.Query().Get(probeAccountParam)
+
+		if pathName == "" && address == "" {
+			http.Error(
+				w,
+				fmt.Sprintf("'%s' or '%s' parameter must be specified", probePathParam, probeAccountParam),
+				http.StatusBadRequest,
+			)
+
+			return
+		}
+
+		ctx, cancel := probeContext(r)
+		defer cancel()
+
+		registry := prometheus.NewRegistry()
+		rpcs := targets.RPCs()
+		store := collector.NewStatusStore()
+
+		if pathName != "" {
+			path := targets.Path(pathName)
+			if path == nil {
+				http.Error(w, fmt.Sprintf("unknown path %q", pathName), http.StatusNotFound)
+				return
+			}
+
+			registry.MustRegister(collector.IBCCollector{
+				RPCs:          rpcs,
+				Paths:         []*config.IBCData{path},
+				ErrorPolicies: policies,
+				Cache:         collector.NewSampleCache(),
+				Status:        store,
+				Ctx:           ctx,
+			})
+		}
+
+		if address != "" {
+			accounts := targets.AccountsByAddress(address)
+			if len(accounts) == 0 {
+				http.Error(w, fmt.Sprintf("unknown account %q", address), http.StatusNotFound)
+				return
+			}
+
+			registry.MustRegister(collector.WalletBalanceCollector{
+				RPCs:          rpcs,
+				Accounts:      accounts,
+				ErrorPolicies: policies,
+				Cache:         collector.NewSampleCache(),
+				Status:        store,
+				Denoms:        denoms,
+				Ctx:           ctx,
+			})
+		}
+
+		log.Debug("Start probe", zap.String("path", pathName), zap.String("account", address))
+
+		start := time.Now()
+
+		mfs, err := registry.Gather()
+		if err != nil {
+			log.Error(err.Error())
+		}
+
+		probeSuccess := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_success",
+			Help: "Returns 1 if all queries of the probe succeeded.",
+		})
+
+		if err == nil && probeSucceeded(store) {
+			probeSuccess.Set(1)
+		}
+
+		probeDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_duration_seconds",
+			Help: "Returns how long the probe took to complete in seconds.",
+		})
+		probeDuration.Set(time.Since(start).Seconds())
+
+		probeRegistry := prometheus.NewRegistry()
+		probeRegistry.MustRegister(probeSuccess, probeDuration)
+
+		// Serve already gathered metrics so RPC queries are not repeated.
+		gatherers := prometheus.Gatherers{
+			prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return mfs, nil }),
+			probeRegistry,
+		}
+
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
+
+// probeContext returns context of request r canceled shortly before the
+// scrape timeout set by Prometheus, if any.
+func probeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	seconds, err := strconv.ParseFloat(r.Header.Get(scrapeTimeoutHeader), 64)
+	if err != nil || seconds <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > scrapeTimeoutOffset {
+		timeout -= scrapeTimeoutOffset
+	}
+
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// probeSucceeded reports if paths and accounts in store of a probe were
+// collected without errors.
+func probeSucceeded(store *collector.StatusStore) bool {
+	for _, p := range store.Paths() {
+		if p.LastError != "" {
+			return false
+		}
+	}
+
+	for _, a := range store.Accounts() {
+		if a.LastError != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,294 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+func testTargets() *Targets {
+	targets := &Targets{}
+	targets.SetPaths(&map[string]config.RPC{}, []*config.IBCData{
+		{
+			Chain1: config.IBCChainMeta{ChainName: "archway"},
+			Chain2: config.IBCChainMeta{ChainName: "osmosis"},
+		},
+	})
+	targets.SetAccounts(&map[string]config.RPC{}, []*config.Account{
+		{Address: "archway1abc", ChainName: "archway", Denom: "aarch"},
+	})
+
+	return targets
+}
+
+func TestTargetsPath(t *testing.T) {
+	targets := testTargets()
+
+	testCases := []struct {
+		name  string
+		path  string
+		found bool
+	}{
+		{name: "Registry Order", path: "archway-osmosis", found: true},
+		{name: "Reversed Order", path: "osmosis-archway", found: true},
+		{name: "Unknown Path", path: "archway-noble", found: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := targets.Path(tc.path)
+			assert.Equal(t, tc.found, res != nil)
+		})
+	}
+}
+
+func TestProbeHandlerBadRequests(t *testing.T) {
+	handler := ProbeHandler(testTargets(), config.ErrorPolicies{}, nil)
+
+	testCases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "Missing Params", query: "", status: http.StatusBadRequest},
+		{name: "Unknown Path", query: "?path=archway-noble", status: http.StatusNotFound},
+		{name: "Unknown Account", query: "?account=archway1xyz", status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe"+tc.query, nil))
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestProbeContext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		deadline bool
+		timeout  time.Duration
+	}{
+		{name: "No Scrape Timeout", header: "", deadline: false},
+		{name: "Invalid Scrape Timeout", header: "soon", deadline: false},
+		{name: "Scrape Timeout", header: "10", deadline: true, timeout: 9500 * time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/probe?path=archway-osmosis", nil)
+			r.Header.Set(scrapeTimeoutHeader, tc.header)
+
+			start := time.Now()
+			ctx, cancel := probeContext(r)
+
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tc.deadline, ok)
+
+			if tc.deadline {
+				assert.WithinDuration(t, start.Add(tc.timeout), deadline, time.Second)
+			}
+		})
+	}
+}
+
+func TestProbeSucceeded(t *testing.T) {
+	store := collector.NewStatusStore()
+	store.UpdatePath(collector.PathStatus{Name: "archway-osmosis"})
+	store.UpdateAccount(collector.AccountStatus{Address: "archway1abc", ChainName: "archway", Denom: "aarch"})
+
+	assert.True(t, probeSucceeded(store))
+
+	store.UpdateAccount(collector.AccountStatus{
+		Address: "archway1abc", ChainName: "archway", Denom: "aarch", LastError: "context deadline exceeded",
+	})
+
+	assert.False(t, probeSucceeded(store))
+}
+
+func TestSortPaths(t *testing.T) {
+	earlier := time.Unix(1700000000, 0)
+	later := time.Unix(1800000000, 0)
+
+	statuses := []collector.PathStatus{
+		{Name: "a", Chain1: collector.ChainStatus{ClientExpiry: &later}},
+		{Name: "b", Channels: []collector.ChannelStatus{{DstStuckPackets: 5}}},
+		{
+			Name:     "c",
+			Chain1:   collector.ChainStatus{ClientExpiry: &earlier},
+			Channels: []collector.ChannelStatus{{SrcStuckPackets: 2}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		by       string
+		expected []string
+	}{
+		{name: "By Name", by: sortByName, expected: []string{"a", "b", "c"}},
+		{name: "By Expiry", by: sortByExpiry, expected: []string{"c", "a", "b"}},
+		{name: "By Stuck Packets", by: sortByStuck, expected: []string{"b", "c", "a"}},
+		{name: "Default", by: "", expected: []string{"a", "b", "c"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := append([]collector.PathStatus{}, statuses...)
+			sortPaths(res, tc.by)
+
+			names := []string{}
+			for _, s := range res {
+				names = append(names, s.Name)
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestPathsHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	PathsHandler(testTargets(), collector.NewStatusStore()).ServeHTTP(
+		rec, httptest.NewRequest(http.MethodGet, "/api/v1/paths", nil),
+	)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	res := []collector.PathStatus{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Len(t, res, 1)
+	assert.Equal(t, "archway-osmosis", res[0].Name)
+	assert.Nil(t, res[0].Chain1.ClientExpiry)
+}
+
+func TestPacketsHandler(t *testing.T) {
+	store := collector.NewStatusStore()
+	store.UpdatePath(collector.PathStatus{
+		Name:   "archway-osmosis",
+		Chain1: collector.ChainStatus{ChainName: "archway"},
+		Chain2: collector.ChainStatus{ChainName: "osmosis"},
+	})
+
+	handler := PacketsHandler(testTargets(), store, collector.NewPacketCache())
+
+	testCases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "Missing Path", query: "", status: http.StatusBadRequest},
+		{name: "Unknown Path", query: "?path=archway-noble", status: http.StatusNotFound},
+		{name: "No Stuck Packets", query: "?path=osmosis-archway", status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/packets"+tc.query, nil))
+			assert.Equal(t, tc.status, rec.Code)
+
+			if tc.status == http.StatusOK {
+				assert.JSONEq(t, "[]", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRoutesHandler(t *testing.T) {
+	targets := testTargets()
+	targets.SetRoutes([]*config.ForwardRoute{
+		{Name: "archway-noble", Chains: []string{"archway", "osmosis", "noble"}},
+	})
+
+	rec := httptest.NewRecorder()
+	RoutesHandler(targets, collector.NewStatusStore(), nil).ServeHTTP(
+		rec, httptest.NewRequest(http.MethodGet, "/api/v1/routes", nil),
+	)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	res := []collector.RouteStatus{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Len(t, res, 1)
+	assert.True(t, res[0].Degraded)
+	assert.Len(t, res[0].Hops, 2)
+	assert.Equal(t, []string{"path not collected yet"}, res[0].Hops[0].Reasons)
+	assert.Equal(t, []string{"path not found in registry"}, res[0].Hops[1].Reasons)
+}
+
+func TestStatusHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	StatusHandler(testTargets(), collector.NewStatusStore()).ServeHTTP(
+		rec, httptest.NewRequest(http.MethodGet, "/status", nil),
+	)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "archway-osmosis")
+}
+
+func TestReadyzHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		registryErr error
+		chains      map[string]error
+		status      int
+	}{
+		{
+			name:   "Ready",
+			chains: map[string]error{"archway": nil, "osmosis": errors.New("timeout")},
+			status: http.StatusOK,
+		},
+		{
+			name:        "Registry Fetch Failed",
+			registryErr: errors.New("bad credentials"),
+			chains:      map[string]error{"archway": nil},
+			status:      http.StatusServiceUnavailable,
+		},
+		{
+			name:   "Chains Not Checked",
+			chains: nil,
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name: "Not Enough Reachable Chains",
+			chains: map[string]error{
+				"archway": nil,
+				"osmosis": errors.New("timeout"),
+				"noble":   errors.New("timeout"),
+			},
+			status: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHealth(50)
+			h.SetRegistry(tc.registryErr)
+
+			if tc.chains != nil {
+				h.chains = tc.chains
+				h.chainsChecked = true
+			}
+
+			rec := httptest.NewRecorder()
+			ReadyzHandler(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.status, rec.Code)
+
+			res := readiness{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, tc.status == http.StatusOK, res.Ready)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/archway-network/relayer_exporter/pkg/collector"
+	log "github.com/archway-network/relayer_exporter/pkg/logger"
+)
+
+//go:embed templates/status.html
+var templates embed.FS
+
+var statusTemplate = template.Must(
+	template.New("status.html").Funcs(template.FuncMap{
+		"expiry": func(t *time.Time) string {
+			if t == nil {
+				return "unknown"
+			}
+
+			return t.UTC().Format(time.RFC3339)
+		},
+		"timeLeft": func(t *time.Time) string {
+			if t == nil {
+				return "unknown"
+			}
+
+			return time.Until(*t).Truncate(time.Minute).String()
+		},
+	}).ParseFS(templates, "templates/status.html"),
+)
+
+// StatusHandler returns handler rendering HTML status page of all monitored
+// paths, sorted by time to expiry unless ?sort=name|stuck is given.
+func StatusHandler(targets *Targets, store *collector.StatusStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sortBy := r.URL.Query().Get("sort")
+		if sortBy == "" {
+			sortBy = sortByExpiry
+		}
+
+		statuses := pathsStatus(targets, store)
+		sortPaths(statuses, sortBy)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		err := statusTemplate.Execute(w, struct {
+			Now   time.Time
+			Paths []collector.PathStatus
+		}{
+			Now:   time.Now(),
+			Paths: statuses,
+		})
+		if err != nil {
+			log.Error(err.Error())
+		}
+	})
+}
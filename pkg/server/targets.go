@@ -0,0 +1,97 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/archway-network/relayer_exporter/pkg/config"
+)
+
+// Targets holds IBC paths and accounts currently monitored by the exporter.
+// It is updated on every configuration refresh.
+type Targets struct {
+	mu       sync.RWMutex
+	rpcs     *map[string]config.RPC
+	paths    []*config.IBCData
+	accounts []*config.Account
+	routes   []*config.ForwardRoute
+}
+
+func (t *Targets) SetPaths(rpcs *map[string]config.RPC, paths []*config.IBCData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rpcs = rpcs
+	t.paths = paths
+}
+
+func (t *Targets) SetAccounts(rpcs *map[string]config.RPC, accounts []*config.Account) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rpcs = rpcs
+	t.accounts = accounts
+}
+
+func (t *Targets) SetRoutes(routes []*config.ForwardRoute) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.routes = routes
+}
+
+func (t *Targets) RPCs() *map[string]config.RPC {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.rpcs == nil {
+		return &map[string]config.RPC{}
+	}
+
+	return t.rpcs
+}
+
+func (t *Targets) Paths() []*config.IBCData {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.paths
+}
+
+func (t *Targets) Accounts() []*config.Account {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.accounts
+}
+
+func (t *Targets) Routes() []*config.ForwardRoute {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.routes
+}
+
+// Path returns IBC path matching name in <chain1>-<chain2> format where
+// chains can be given in any order.
+func (t *Targets) Path(name string) *config.IBCData {
+	for _, p := range t.Paths() {
+		if name == p.Name() || name == p.Chain2.ChainName+"-"+p.Chain1.ChainName {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// AccountsByAddress returns all configured accounts with address.
+func (t *Targets) AccountsByAddress(address string) []*config.Account {
+	accounts := []*config.Account{}
+
+	for _, a := range t.Accounts() {
+		if a.Address == address {
+			accounts = append(accounts, a)
+		}
+	}
+
+	return accounts
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const openTimeout = time.Second
+
+// Store is an embedded key-value store persisting exporter state across
+// restarts. Values are stored as JSON in buckets. All methods of a nil
+// Store are no-ops, so state is kept in memory only when no store is
+// configured.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens store file at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+// Get decodes value of key in bucket into v. It returns false if the key
+// doesn't exist.
+func (s *Store) Get(bucket, key string, v any) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+
+	var value []byte
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		if data := b.Get([]byte(key)); data != nil {
+			value = append([]byte{}, data...)
+		}
+
+		return nil
+	})
+	if err != nil || value == nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(value, v)
+}
+
+// Put stores v as value of key in bucket.
+func (s *Store) Put(bucket, key string, v any) error {
+	return s.PutAll(bucket, map[string]any{key: v})
+}
+
+// PutAll stores all values in bucket in a single transaction.
+func (s *Store) PutAll(bucket string, values map[string]any) error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+
+		for key, v := range values {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) Delete(bucket, key string) error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.Delete([]byte(key))
+	})
+}
+
+// ForEach calls fn with every key and raw JSON value in bucket.
+func (s *Store) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	if s == nil {
+		return nil
+	}
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
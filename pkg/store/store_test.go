@@ -0,0 +1,60 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type value struct {
+	Height int64 `json:"height"`
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relayer_exporter.db")
+
+	s, err := Open(path)
+	assert.NoError(t, err)
+
+	v := value{}
+	found, err := s.Get("heights", "archway", &v)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, s.PutAll("heights", map[string]any{
+		"archway": value{Height: 100},
+		"osmosis": value{Height: 200},
+	}))
+	assert.NoError(t, s.Delete("heights", "osmosis"))
+	assert.NoError(t, s.Close())
+
+	s, err = Open(path)
+	assert.NoError(t, err)
+
+	defer s.Close()
+
+	found, err = s.Get("heights", "archway", &v)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, value{Height: 100}, v)
+
+	keys := []string{}
+	assert.NoError(t, s.ForEach("heights", func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Equal(t, []string{"archway"}, keys)
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+
+	found, err := s.Get("heights", "archway", &value{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.NoError(t, s.Put("heights", "archway", value{Height: 100}))
+	assert.NoError(t, s.Delete("heights", "archway"))
+	assert.NoError(t, s.ForEach("heights", func(string, []byte) error { return nil }))
+	assert.NoError(t, s.Close())
+}